@@ -0,0 +1,320 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxArchiveContent bounds the total uncompressed size of the members read
+// from one archive.
+const maxArchiveContent = 4 * maxContentSize
+
+// errArchiveTooLarge is returned when an archive expands beyond maxArchiveContent.
+var errArchiveTooLarge = errors.New("archive too large to compare")
+
+// archiveMember is one entry of a zip, tar or ar archive.
+type archiveMember struct {
+	name    string
+	content []byte
+	sum     [sha256.Size]byte
+	// metadata lists the entry's attributes other than its content, as
+	// name/value pairs in a fixed order per format.
+	metadata [][2]string
+}
+
+// archiveFormat returns the archive format of data ("zip", "tar", "tar.gz"
+// or "ar"), or "" if it is not an archive this tool can open.
+func archiveFormat(path string, data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")), bytes.HasPrefix(data, []byte("PK\x05\x06")):
+		return "zip"
+	case bytes.HasPrefix(data, []byte("!<arch>\n")):
+		return "ar"
+	case len(data) >= 262 && string(data[257:262]) == "ustar":
+		return "tar"
+	case bytes.HasPrefix(data, gzipMagic) && (strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")):
+		return "tar.gz"
+	}
+	return ""
+}
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// readArchive returns the members of an archive in format.
+func readArchive(format string, data []byte) ([]archiveMember, error) {
+	switch format {
+	case "zip":
+		return readZip(data)
+	case "tar":
+		return readTar(bytes.NewReader(data))
+	case "tar.gz":
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return readTar(r)
+	case "ar":
+		return readAr(data)
+	}
+	return nil, fmt.Errorf("unsupported archive format %q", format)
+}
+
+// readAll reads r into a member, charging its size against *budget.
+func readAll(r io.Reader, budget *int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, *budget+1))
+	if err != nil {
+		return nil, err
+	}
+	*budget -= int64(len(data))
+	if *budget < 0 {
+		return nil, errArchiveTooLarge
+	}
+	return data, nil
+}
+
+func newMember(name string, content []byte, metadata ...[2]string) archiveMember {
+	return archiveMember{name: name, content: content, sum: sha256.Sum256(content), metadata: metadata}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func readZip(data []byte) ([]archiveMember, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	budget := int64(maxArchiveContent)
+	var members []archiveMember
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		content, err := readAll(rc, &budget)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		members = append(members, newMember(f.Name, content,
+			[2]string{"mtime", formatTime(f.Modified)},
+			[2]string{"mode", f.Mode().String()},
+			[2]string{"method", strconv.Itoa(int(f.Method))},
+			[2]string{"extra", fmt.Sprintf("%x", f.Extra)},
+			[2]string{"comment", f.Comment},
+		))
+	}
+	return members, nil
+}
+
+func readTar(r io.Reader) ([]archiveMember, error) {
+	tr := tar.NewReader(r)
+	budget := int64(maxArchiveContent)
+	var members []archiveMember
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return members, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		content, err := readAll(tr, &budget)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", hdr.Name, err)
+		}
+		members = append(members, newMember(hdr.Name, content,
+			[2]string{"type", string(hdr.Typeflag)},
+			[2]string{"linkname", hdr.Linkname},
+			[2]string{"mtime", formatTime(hdr.ModTime)},
+			[2]string{"mode", fmt.Sprintf("%o", hdr.Mode)},
+			[2]string{"uid", strconv.Itoa(hdr.Uid)},
+			[2]string{"gid", strconv.Itoa(hdr.Gid)},
+			[2]string{"uname", hdr.Uname},
+			[2]string{"gname", hdr.Gname},
+		))
+	}
+}
+
+// readAr reads a Unix ar archive in the GNU or BSD variant, as produced by
+// static library rules.
+func readAr(data []byte) ([]archiveMember, error) {
+	const headerSize = 60
+	data = data[len("!<arch>\n"):]
+	var longNames []byte
+	var members []archiveMember
+	for len(data) > 0 {
+		if len(data) < headerSize {
+			return nil, fmt.Errorf("truncated ar header")
+		}
+		hdr := data[:headerSize]
+		if string(hdr[58:60]) != "`\n" {
+			return nil, fmt.Errorf("bad ar header magic")
+		}
+		field := func(from, to int) string { return strings.TrimRight(string(hdr[from:to]), " ") }
+		size, err := strconv.ParseInt(field(48, 58), 10, 64)
+		if err != nil || size < 0 || int64(len(data)-headerSize) < size {
+			return nil, fmt.Errorf("bad ar member size %q", field(48, 58))
+		}
+		content := data[headerSize : headerSize+int(size)]
+		data = data[headerSize+int(size):]
+		if size%2 == 1 && len(data) > 0 {
+			data = data[1:]
+		}
+
+		name := field(0, 16)
+		switch {
+		case name == "//":
+			// GNU table of long member names.
+			longNames = content
+			continue
+		case name == "/" || name == "/SYM64/" || name == "__.SYMDEF" || name == "__.SYMDEF SORTED":
+			// Symbol table: include it, since its content is part of the output.
+		case strings.HasPrefix(name, "#1/"):
+			// BSD long name stored at the start of the content.
+			n, err := strconv.Atoi(name[3:])
+			if err != nil || n > len(content) {
+				return nil, fmt.Errorf("bad BSD ar name %q", name)
+			}
+			name = strings.TrimRight(string(content[:n]), "\x00")
+			content = content[n:]
+		case strings.HasPrefix(name, "/"):
+			// GNU reference into the long name table.
+			off, err := strconv.Atoi(name[1:])
+			if err != nil || off > len(longNames) {
+				return nil, fmt.Errorf("bad GNU ar name %q", name)
+			}
+			end := bytes.IndexByte(longNames[off:], '\n')
+			if end < 0 {
+				end = len(longNames) - off
+			}
+			name = strings.TrimSuffix(string(longNames[off:off+end]), "/")
+		default:
+			name = strings.TrimSuffix(name, "/")
+		}
+		members = append(members, newMember(name, content,
+			[2]string{"mtime", field(16, 28)},
+			[2]string{"uid", field(28, 34)},
+			[2]string{"gid", field(34, 40)},
+			[2]string{"mode", field(40, 48)},
+		))
+	}
+	return members, nil
+}
+
+// memberKeys returns a unique key per member: its name, followed by "#n" for
+// the n-th repetition of a name, as ar archives may repeat names.
+func memberKeys(members []archiveMember) []string {
+	seen := make(map[string]int)
+	keys := make([]string, len(members))
+	for i, m := range members {
+		seen[m.name]++
+		keys[i] = m.name
+		if n := seen[m.name]; n > 1 {
+			keys[i] = fmt.Sprintf("%s#%d", m.name, n)
+		}
+	}
+	return keys
+}
+
+// archiveDiff compares two archives member by member and returns detail
+// lines listing added, removed and moved members, members whose content
+// changed, and members that differ only in metadata.
+func archiveDiff(format string, a, b []byte) []string {
+	ma, err := readArchive(format, a)
+	if err != nil {
+		return []string{fmt.Sprintf("(cannot read %s archive: %v)", format, err)}
+	}
+	mb, err := readArchive(format, b)
+	if err != nil {
+		return []string{fmt.Sprintf("(cannot read %s archive: %v)", format, err)}
+	}
+	keysA, keysB := memberKeys(ma), memberKeys(mb)
+	indexA := make(map[string]int)
+	for i, k := range keysA {
+		indexA[k] = i
+	}
+	indexB := make(map[string]int)
+	for i, k := range keysB {
+		indexB[k] = i
+	}
+
+	lines := []string{fmt.Sprintf("%s archive: %d -> %d members", format, len(ma), len(mb))}
+	for i, k := range keysA {
+		if _, ok := indexB[k]; !ok {
+			lines = append(lines, fmt.Sprintf("removed: %s (%d bytes)", k, len(ma[i].content)))
+		}
+	}
+	for i, k := range keysB {
+		if _, ok := indexA[k]; !ok {
+			lines = append(lines, fmt.Sprintf("added: %s (%d bytes)", k, len(mb[i].content)))
+		}
+	}
+
+	// Members kept in order form the longest common subsequence of the
+	// names present in both archives; the others moved.
+	var commonA, commonB []string
+	for _, k := range keysA {
+		if _, ok := indexB[k]; ok {
+			commonA = append(commonA, k)
+		}
+	}
+	for _, k := range keysB {
+		if _, ok := indexA[k]; ok {
+			commonB = append(commonB, k)
+		}
+	}
+	if edits, ok := diffStrings(commonA, commonB); ok {
+		for _, e := range edits {
+			if e.op == opInsert {
+				k := commonB[e.bIndex]
+				lines = append(lines, fmt.Sprintf("moved: %s (position %d -> %d)", k, indexA[k]+1, indexB[k]+1))
+			}
+		}
+	} else {
+		lines = append(lines, "(member order differs too much to list moves)")
+	}
+
+	for _, k := range commonA {
+		x, y := ma[indexA[k]], mb[indexB[k]]
+		if x.sum != y.sum {
+			lines = append(lines, fmt.Sprintf("changed: %s (%d -> %d bytes)", k, len(x.content), len(y.content)))
+			for _, line := range memberContentDiff(x.content, y.content) {
+				lines = append(lines, "  "+line)
+			}
+			continue
+		}
+		var changed []string
+		for i := range x.metadata {
+			if x.metadata[i] != y.metadata[i] {
+				changed = append(changed, fmt.Sprintf("%s %q -> %q", x.metadata[i][0], x.metadata[i][1], y.metadata[i][1]))
+			}
+		}
+		if len(changed) > 0 {
+			lines = append(lines, fmt.Sprintf("metadata only: %s: %s", k, strings.Join(changed, ", ")))
+		}
+	}
+	return lines
+}
+
+// memberContentDiff describes how the content of an archive member changed.
+func memberContentDiff(a, b []byte) []string {
+	if isELF(a) && isELF(b) {
+		return elfDiff(a, b)
+	}
+	if isText(a) && isText(b) {
+		return unifiedDiff(splitLines(a), splitLines(b), diffContext)
+	}
+	return binarySummary(a, b)
+}
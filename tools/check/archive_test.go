@@ -0,0 +1,182 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testMember struct {
+	name    string
+	content string
+	mtime   time.Time
+	uid     int
+}
+
+var epoch = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func makeZip(t *testing.T, members []testMember) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, m := range members {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: m.name, Method: zip.Deflate, Modified: m.mtime})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(m.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func makeTar(t *testing.T, members []testMember) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, m := range members {
+		hdr := &tar.Header{Name: m.name, Mode: 0644, Size: int64(len(m.content)), ModTime: m.mtime, Uid: m.uid, Format: tar.FormatUSTAR}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(m.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// makeAr writes a GNU ar archive, with a long name table if needed.
+func makeAr(members []testMember) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("!<arch>\n")
+	var longNames strings.Builder
+	names := make([]string, len(members))
+	for i, m := range members {
+		if len(m.name) > 15 {
+			names[i] = fmt.Sprintf("/%d", longNames.Len())
+			longNames.WriteString(m.name + "/\n")
+		} else {
+			names[i] = m.name + "/"
+		}
+	}
+	writeMember := func(name, mtime, uid string, content string) {
+		fmt.Fprintf(&buf, "%-16s%-12s%-6s%-6s%-8s%-10d`\n", name, mtime, uid, "0", "644", len(content))
+		buf.WriteString(content)
+		if len(content)%2 == 1 {
+			buf.WriteByte('\n')
+		}
+	}
+	if longNames.Len() > 0 {
+		writeMember("//", "", "", longNames.String())
+	}
+	for i, m := range members {
+		writeMember(names[i], fmt.Sprint(m.mtime.Unix()), fmt.Sprint(m.uid), m.content)
+	}
+	return buf.Bytes()
+}
+
+func TestArchiveFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		data []byte
+		want string
+	}{
+		{"out/lib.jar", makeZip(t, nil), "zip"},
+		{"out/a.tar", makeTar(t, []testMember{{name: "a", mtime: epoch}}), "tar"},
+		{"out/liba.a", makeAr(nil), "ar"},
+		{"out/a.txt", []byte("hello"), ""},
+		{"out/a.gz", gzipMagic, ""},
+	}
+	for _, tt := range tests {
+		if got := archiveFormat(tt.path, tt.data); got != tt.want {
+			t.Errorf("archiveFormat(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestArchiveDiff_Zip(t *testing.T) {
+	later := epoch.Add(48 * time.Hour)
+	a := makeZip(t, []testMember{
+		{name: "META-INF/MANIFEST.MF", content: "Manifest-Version: 1.0\n", mtime: epoch},
+		{name: "com/A.class", content: "A", mtime: epoch},
+		{name: "com/B.class", content: "B", mtime: epoch},
+		{name: "com/Gone.class", content: "G", mtime: epoch},
+	})
+	b := makeZip(t, []testMember{
+		{name: "META-INF/MANIFEST.MF", content: "Manifest-Version: 1.0\nBuilt-By: ci\n", mtime: epoch},
+		{name: "com/B.class", content: "B", mtime: epoch},
+		{name: "com/A.class", content: "A", mtime: later},
+		{name: "com/New.class", content: "N", mtime: epoch},
+	})
+
+	joined := strings.Join(archiveDiff("zip", a, b), "\n")
+	for _, want := range []string{
+		"zip archive: 4 -> 4 members",
+		"removed: com/Gone.class (1 bytes)",
+		"added: com/New.class (1 bytes)",
+		"moved: com/A.class (position 2 -> 3)",
+		"changed: META-INF/MANIFEST.MF (22 -> 35 bytes)",
+		"  +Built-By: ci",
+		`metadata only: com/A.class: mtime "1980-01-01T00:00:00Z" -> "1980-01-03T00:00:00Z"`,
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing %q in:\n%s", want, joined)
+		}
+	}
+	if strings.Contains(joined, "B.class") {
+		t.Errorf("unchanged member B.class should not be reported:\n%s", joined)
+	}
+}
+
+func TestArchiveDiff_Tar(t *testing.T) {
+	a := makeTar(t, []testMember{{name: "bin/tool", content: "x", mtime: epoch, uid: 0}})
+	b := makeTar(t, []testMember{{name: "bin/tool", content: "x", mtime: epoch, uid: 1000}})
+
+	joined := strings.Join(archiveDiff("tar", a, b), "\n")
+	if !strings.Contains(joined, `metadata only: bin/tool: uid "0" -> "1000"`) {
+		t.Errorf("expected a uid difference, got:\n%s", joined)
+	}
+}
+
+func TestArchiveDiff_Ar(t *testing.T) {
+	long := "a_rather_long_object_file_name.o"
+	a := makeAr([]testMember{{name: "x.o", content: "xx", mtime: epoch}, {name: long, content: "yyy", mtime: epoch}})
+	b := makeAr([]testMember{{name: "x.o", content: "xx", mtime: epoch.Add(time.Second)}, {name: long, content: "yyz", mtime: epoch}})
+
+	members, err := readAr(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 || members[1].name != long || string(members[1].content) != "yyy" {
+		t.Fatalf("readAr: got %+v", members)
+	}
+
+	joined := strings.Join(archiveDiff("ar", a, b), "\n")
+	for _, want := range []string{
+		`metadata only: x.o: mtime "315532800" -> "315532801"`,
+		"changed: " + long + " (3 -> 3 bytes)",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing %q in:\n%s", want, joined)
+		}
+	}
+}
+
+func TestArchiveDiff_Unreadable(t *testing.T) {
+	got := archiveDiff("zip", []byte("PK\x03\x04garbage"), makeZip(t, nil))
+	if len(got) != 1 || !strings.HasPrefix(got[0], "(cannot read zip archive:") {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	pb "tools/execlog/proto"
+)
+
+// argContext is the number of unchanged arguments shown around each change
+// of the command line.
+const argContext = 2
+
+// argUnit is one command argument, or a flag together with its value, so
+// that a changed value is one change to its flag.
+type argUnit struct {
+	// index is the position of the first argument of the unit.
+	index int
+	// args holds the argument, or a flag followed by its separate value.
+	args []string
+	// flag is the flag name, e.g. "--copt" for "--copt=-O2" or "-o" for
+	// "-o out.o"; empty for positional arguments.
+	flag string
+	// value is the flag's value, if it has one.
+	value    string
+	hasValue bool
+}
+
+// quoted formats the arguments of the unit, e.g. `"-o" "out.o"`.
+func (u argUnit) quoted() string {
+	quoted := make([]string, len(u.args))
+	for i, arg := range u.args {
+		quoted[i] = fmt.Sprintf("%q", arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// isFlag reports whether arg looks like a flag rather than a value.
+func isFlag(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+		return false
+	}
+	// Negative numbers are values.
+	return !strings.ContainsAny(arg[1:2], "0123456789.")
+}
+
+// splitArgs groups args, which start at position offset of a command line,
+// into units. The executable at position 0 is never a flag. A flag without
+// "=" takes the next argument as its value unless that is a flag too.
+func splitArgs(args []string, offset int) []argUnit {
+	var units []argUnit
+	for i := 0; i < len(args); {
+		arg := args[i]
+		u := argUnit{index: offset + i, args: []string{arg}}
+		switch {
+		case offset+i == 0 || !isFlag(arg):
+		case strings.Contains(arg, "="):
+			u.flag, u.value, _ = strings.Cut(arg, "=")
+			u.hasValue = true
+		case i+1 < len(args) && !isFlag(args[i+1]):
+			u.flag, u.value, u.hasValue = arg, args[i+1], true
+			u.args = append(u.args, args[i+1])
+		default:
+			u.flag = arg
+		}
+		units = append(units, u)
+		i += len(u.args)
+	}
+	return units
+}
+
+// argEdit is one step of the alignment of two command lines. a is nil for
+// added units, b for removed ones.
+type argEdit struct {
+	// change is nil for unchanged arguments.
+	change *change
+	a, b   *argUnit
+}
+
+// argEdits aligns the command lines a and b argument by argument. The
+// arguments of each run of deletions and insertions are grouped into units,
+// and units for the same flag and then the remaining units in order are
+// paired up as substitutions. A run that replaces the value after an
+// unchanged flag, as in "-o a.o" to "-o b.o", changes that flag.
+func argEdits(a, b []string) []argEdit {
+	edits, ok := diffStrings(a, b)
+	if !ok {
+		// Too different to align: replace everything.
+		edits = nil
+		for i := range a {
+			edits = append(edits, edit{op: opDelete, aIndex: i, bIndex: -1})
+		}
+		for i := range b {
+			edits = append(edits, edit{op: opInsert, aIndex: -1, bIndex: i})
+		}
+	}
+
+	var result []argEdit
+	for i := 0; i < len(edits); {
+		if e := edits[i]; e.op == opEqual {
+			result = append(result, argEdit{
+				a: &argUnit{index: e.aIndex, args: a[e.aIndex : e.aIndex+1]},
+				b: &argUnit{index: e.bIndex, args: b[e.bIndex : e.bIndex+1]},
+			})
+			i++
+			continue
+		}
+		// Deleted and inserted arguments are each contiguous within a run.
+		aStart, aEnd, bStart, bEnd := -1, -1, -1, -1
+		for ; i < len(edits) && edits[i].op != opEqual; i++ {
+			if e := edits[i]; e.op == opDelete {
+				if aStart < 0 {
+					aStart = e.aIndex
+				}
+				aEnd = e.aIndex + 1
+			} else {
+				if bStart < 0 {
+					bStart = e.bIndex
+				}
+				bEnd = e.bIndex + 1
+			}
+		}
+		var deleted, inserted []argUnit
+		if aStart >= 0 {
+			deleted = splitArgs(a[aStart:aEnd], aStart)
+		}
+		if bStart >= 0 {
+			inserted = splitArgs(b[bStart:bEnd], bStart)
+		}
+		if len(deleted) > 0 && len(inserted) > 0 && aStart > 0 {
+			prev := a[aStart-1]
+			if d, in := &deleted[0], &inserted[0]; isFlag(prev) && !strings.Contains(prev, "=") && d.flag == "" && in.flag == "" {
+				d.flag, d.value, d.hasValue = prev, d.args[0], true
+				in.flag, in.value, in.hasValue = prev, in.args[0], true
+			}
+		}
+		result = append(result, pairArgUnits(deleted, inserted)...)
+	}
+	return result
+}
+
+// pairArgUnits turns a run of deleted and inserted units into changes.
+func pairArgUnits(deletedUnits, insertedUnits []argUnit) []argEdit {
+	var deleted, inserted []*argUnit
+	for i := range deletedUnits {
+		deleted = append(deleted, &deletedUnits[i])
+	}
+	for i := range insertedUnits {
+		inserted = append(inserted, &insertedUnits[i])
+	}
+	partner := make(map[*argUnit]*argUnit)
+	used := make(map[*argUnit]bool)
+	for _, d := range deleted {
+		for _, in := range inserted {
+			if d.flag != "" && d.flag == in.flag && !used[in] {
+				partner[d], used[in] = in, true
+				break
+			}
+		}
+	}
+	var free []*argUnit
+	for _, in := range inserted {
+		if !used[in] {
+			free = append(free, in)
+		}
+	}
+	for _, d := range deleted {
+		if partner[d] == nil && len(free) > 0 {
+			partner[d], used[free[0]] = free[0], true
+			free = free[1:]
+		}
+	}
+
+	var result []argEdit
+	for _, d := range deleted {
+		if in := partner[d]; in != nil {
+			result = append(result, argEdit{change: argChange(d, in), a: d, b: in})
+		} else {
+			result = append(result, argEdit{change: argChange(d, nil), a: d})
+		}
+	}
+	for _, in := range inserted {
+		if !used[in] {
+			result = append(result, argEdit{change: argChange(nil, in), b: in})
+		}
+	}
+	return result
+}
+
+// argChange describes the change from unit a to unit b, either of which
+// may be nil. A unit with several arguments is one value, space-separated.
+func argChange(a, b *argUnit) *change {
+	value := func(u *argUnit) *string {
+		s := strings.Join(u.args, " ")
+		return &s
+	}
+	switch {
+	case b == nil:
+		return &change{Kind: changeRemoved, Index: &a.index, Flag: a.flag, Old: value(a)}
+	case a == nil:
+		return &change{Kind: changeAdded, Index: &b.index, Flag: b.flag, New: value(b)}
+	}
+	c := &change{Kind: changeChanged, Index: &a.index, Old: value(a), New: value(b)}
+	if b.index != a.index {
+		c.NewIndex = &b.index
+	}
+	if a.flag != "" && a.flag == b.flag && a.hasValue && b.hasValue {
+		c.Flag, c.Old, c.New = a.flag, &a.value, &b.value
+	}
+	return c
+}
+
+// formatArgEdit formats a changed unit of the command line.
+func formatArgEdit(e argEdit) string {
+	c := e.change
+	switch c.Kind {
+	case changeAdded:
+		return fmt.Sprintf("  added [%d]: %s", *c.Index, e.b.quoted())
+	case changeRemoved:
+		return fmt.Sprintf("  removed [%d]: %s", *c.Index, e.a.quoted())
+	}
+	if c.Flag != "" {
+		return fmt.Sprintf("  changed [%d] %s: %q -> %q", *c.Index, c.Flag, *c.Old, *c.New)
+	}
+	return fmt.Sprintf("  changed [%d]: %s -> %s", *c.Index, e.a.quoted(), e.b.quoted())
+}
+
+// commandArgChanges aligns command_args and returns the changes.
+func commandArgChanges(a, b *pb.SpawnExec) []change {
+	return argChanges(a.CommandArgs, b.CommandArgs)
+}
+
+// commandArgDetails returns detail lines describing how command_args
+// differ.
+func commandArgDetails(a, b *pb.SpawnExec) []string {
+	return argDetails(a.CommandArgs, b.CommandArgs)
+}
+
+// argChanges aligns the command lines a and b and returns the changes.
+func argChanges(a, b []string) []change {
+	var changes []change
+	for _, e := range argEdits(a, b) {
+		if e.change != nil {
+			changes = append(changes, *e.change)
+		}
+	}
+	return changes
+}
+
+// argDetails returns detail lines describing how the command lines a and b
+// differ, with argContext unchanged arguments around each change and "..."
+// between the hunks.
+func argDetails(a, b []string) []string {
+	edits := argEdits(a, b)
+	show := make([]bool, len(edits))
+	for i, e := range edits {
+		if e.change == nil {
+			continue
+		}
+		for j := max(i-argContext, 0); j <= min(i+argContext, len(edits)-1); j++ {
+			show[j] = true
+		}
+	}
+	var lines []string
+	for i, e := range edits {
+		if !show[i] {
+			continue
+		}
+		if i > 0 && !show[i-1] && len(lines) > 0 {
+			lines = append(lines, "  ...")
+		}
+		if e.change == nil {
+			lines = append(lines, fmt.Sprintf("  [%d] %s", e.a.index, e.a.quoted()))
+		} else {
+			lines = append(lines, formatArgEdit(e))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestSplitArgs(t *testing.T) {
+	units := splitArgs([]string{"gcc", "-c", "-o", "a.o", "--copt=-O2", "-I", "-1", "src.c", "--", "-x"}, 0)
+	var got []string
+	for _, u := range units {
+		got = append(got, u.flag+"|"+strings.Join(u.args, " "))
+	}
+	want := []string{"|gcc", "-c|-c", "-o|-o a.o", "--copt|--copt=-O2", "-I|-I -1", "|src.c", "|--", "-x|-x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCommandArgDetails(t *testing.T) {
+	args := func(s string) *pb.SpawnExec {
+		return &pb.SpawnExec{CommandArgs: strings.Fields(s)}
+	}
+
+	tests := []struct {
+		name, a, b string
+		want       []string
+	}{
+		{
+			name: "inserted argument",
+			a:    "gcc -c -O2 -Wall -Werror a.c b.c c.c",
+			b:    "gcc -c -O2 -Wall -DHOST=ci -Werror a.c b.c c.c",
+			want: []string{
+				`  [2] "-O2"`,
+				`  [3] "-Wall"`,
+				`  added [4]: "-DHOST=ci"`,
+				`  [4] "-Werror"`,
+				`  [5] "a.c"`,
+			},
+		},
+		{
+			name: "changed flag value",
+			a:    "tool --stamp=1 --out=x",
+			b:    "tool --stamp=2 --out=x",
+			want: []string{
+				`  [0] "tool"`,
+				`  changed [1] --stamp: "1" -> "2"`,
+				`  [2] "--out=x"`,
+			},
+		},
+		{
+			name: "separate flag value",
+			a:    "gcc -c -o bazel-out/a/a.o a.c",
+			b:    "gcc -c -o bazel-out/b/a.o a.c",
+			want: []string{
+				`  [1] "-c"`,
+				`  [2] "-o"`,
+				`  changed [3] -o: "bazel-out/a/a.o" -> "bazel-out/b/a.o"`,
+				`  [4] "a.c"`,
+			},
+		},
+		{
+			name: "distant changes",
+			a:    "tool a b c d e f g h",
+			b:    "tool x b c d e f g y",
+			want: []string{
+				`  [0] "tool"`,
+				`  changed [1]: "a" -> "x"`,
+				`  [2] "b"`,
+				`  [3] "c"`,
+				`  ...`,
+				`  [6] "f"`,
+				`  [7] "g"`,
+				`  changed [8]: "h" -> "y"`,
+			},
+		},
+		{
+			name: "removed flag",
+			a:    "tool --verbose --out=x in",
+			b:    "tool --out=x in",
+			want: []string{
+				`  [0] "tool"`,
+				`  removed [1]: "--verbose"`,
+				`  [2] "--out=x"`,
+				`  [3] "in"`,
+			},
+		},
+		{
+			name: "removed flag with value",
+			a:    "tool -v -o x.o in",
+			b:    "tool -v in",
+			want: []string{
+				`  [0] "tool"`,
+				`  [1] "-v"`,
+				`  removed [2]: "-o" "x.o"`,
+				`  [4] "in"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := commandArgDetails(args(tt.a), args(tt.b))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestCommandArgChanges(t *testing.T) {
+	a := &pb.SpawnExec{CommandArgs: []string{"gcc", "-DA", "-o", "a.o", "a.c"}}
+	b := &pb.SpawnExec{CommandArgs: []string{"gcc", "-DB", "-DA", "-o", "b.o", "a.c"}}
+	changes := commandArgChanges(a, b)
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want 2: %+v", len(changes), changes)
+	}
+	if c := changes[0]; c.Kind != changeAdded || *c.Index != 1 || c.Flag != "-DB" {
+		t.Errorf("got %+v, want -DB added at 1", c)
+	}
+	if c := changes[1]; c.Kind != changeChanged || *c.Index != 3 || *c.NewIndex != 4 || c.Flag != "-o" || *c.Old != "a.o" || *c.New != "b.o" {
+		t.Errorf("got %+v, want -o changed from a.o to b.o", c)
+	}
+}
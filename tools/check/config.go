@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	pb "tools/execlog/proto"
+)
+
+// config is the contents of a --config file.
+type config struct {
+	Suppressions []*suppression `json:"suppressions"`
+}
+
+// suppression marks matching actions as known non-determinism. Every
+// matcher that is set must match; owner and reason are required.
+type suppression struct {
+	// Mnemonic matches the action mnemonic exactly.
+	Mnemonic string `json:"mnemonic,omitempty"`
+	// TargetLabel matches the target label. "//pkg/..." matches a package
+	// and its subpackages; otherwise "*" and "**" act as in Output.
+	TargetLabel string `json:"target_label,omitempty"`
+	// Output is a glob matched against the listed and actual outputs. "*"
+	// does not cross "/", "**" does.
+	Output string `json:"output,omitempty"`
+	// EnvironmentVariable matches if this variable differs between logs.
+	EnvironmentVariable string `json:"environment_variable,omitempty"`
+	// Input is a glob matched against the inputs that differ between logs.
+	Input string `json:"input,omitempty"`
+
+	Owner  string `json:"owner"`
+	Reason string `json:"reason"`
+	// Expires is an optional YYYY-MM-DD date. The suppression applies up to
+	// and including that day.
+	Expires string `json:"expires,omitempty"`
+
+	targetLabel *regexp.Regexp
+	output      *regexp.Regexp
+	input       *regexp.Regexp
+	expires     time.Time
+}
+
+// loadConfig reads and validates the --config file at path.
+func loadConfig(path string) (*config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var c config
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i, s := range c.Suppressions {
+		if err := s.compile(); err != nil {
+			return nil, fmt.Errorf("%s: suppression %d: %w", path, i+1, err)
+		}
+	}
+	return &c, nil
+}
+
+// compile validates s and prepares its patterns.
+func (s *suppression) compile() error {
+	if s.Mnemonic == "" && s.TargetLabel == "" && s.Output == "" && s.EnvironmentVariable == "" && s.Input == "" {
+		return fmt.Errorf("no matcher set (mnemonic, target_label, output, environment_variable or input)")
+	}
+	if s.Owner == "" {
+		return fmt.Errorf("owner is required")
+	}
+	if s.Reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+	if s.Expires != "" {
+		expires, err := time.Parse("2006-01-02", s.Expires)
+		if err != nil {
+			return fmt.Errorf("expires: want YYYY-MM-DD, got %q", s.Expires)
+		}
+		s.expires = expires.AddDate(0, 0, 1)
+	}
+	if s.TargetLabel != "" {
+		if pkg, ok := strings.CutSuffix(s.TargetLabel, "/..."); ok {
+			s.targetLabel = regexp.MustCompile("^" + regexp.QuoteMeta(pkg) + "(/.*)?:.*$")
+		} else {
+			s.targetLabel = globRegexp(s.TargetLabel)
+		}
+	}
+	if s.Output != "" {
+		s.output = globRegexp(s.Output)
+	}
+	if s.Input != "" {
+		s.input = globRegexp(s.Input)
+	}
+	return nil
+}
+
+// globRegexp converts a glob to an anchored regular expression. "*" and "?"
+// do not match "/"; "**" matches any number of path segments.
+func globRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// expired reports whether s no longer applies at now.
+func (s *suppression) expired(now time.Time) bool {
+	return !s.expires.IsZero() && !now.Before(s.expires)
+}
+
+// matches reports whether s matches the non-deterministic action d,
+// regardless of expiry.
+func (s *suppression) matches(d *diffResult) bool {
+	if s.Mnemonic != "" && s.Mnemonic != d.a.Mnemonic {
+		return false
+	}
+	if s.targetLabel != nil && !s.targetLabel.MatchString(d.a.TargetLabel) {
+		return false
+	}
+	if s.output != nil && !anyMatch(s.output, outputPaths(d.a)) {
+		return false
+	}
+	if s.EnvironmentVariable != "" && !d.differsInEnv(s.EnvironmentVariable) {
+		return false
+	}
+	if s.input != nil {
+		var changed []string
+		for _, outlier := range d.outlierExecs {
+			changed = append(changed, changedPaths(d.a.Inputs, outlier.Inputs)...)
+		}
+		if !anyMatch(s.input, changed) {
+			return false
+		}
+	}
+	return true
+}
+
+// describe summarizes the matchers of s, e.g. "mnemonic=Genrule output=out/**".
+func (s *suppression) describe() string {
+	var parts []string
+	if s.Mnemonic != "" {
+		parts = append(parts, "mnemonic="+s.Mnemonic)
+	}
+	if s.TargetLabel != "" {
+		parts = append(parts, "target_label="+s.TargetLabel)
+	}
+	if s.Output != "" {
+		parts = append(parts, "output="+s.Output)
+	}
+	if s.EnvironmentVariable != "" {
+		parts = append(parts, "environment_variable="+s.EnvironmentVariable)
+	}
+	if s.Input != "" {
+		parts = append(parts, "input="+s.Input)
+	}
+	return strings.Join(parts, " ")
+}
+
+// outputPaths returns the listed and actual output paths of exec.
+func outputPaths(exec *pb.SpawnExec) []string {
+	paths := append([]string{}, exec.ListedOutputs...)
+	for _, f := range exec.ActualOutputs {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+// anyMatch reports whether re matches any of paths.
+func anyMatch(re *regexp.Regexp, paths []string) bool {
+	for _, p := range paths {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// differsInEnv reports whether the environment variable name differs between
+// the majority and any outlier.
+func (d *diffResult) differsInEnv(name string) bool {
+	value := func(exec *pb.SpawnExec) (string, bool) {
+		for _, e := range exec.EnvironmentVariables {
+			if e.Name == name {
+				return e.Value, true
+			}
+		}
+		return "", false
+	}
+	va, oka := value(d.a)
+	for _, outlier := range d.outlierExecs {
+		if vb, okb := value(outlier); oka != okb || va != vb {
+			return true
+		}
+	}
+	return false
+}
+
+// suppressor applies the suppressions of a config at a given time.
+type suppressor struct {
+	suppressions []*suppression
+	now          time.Time
+
+	// expiredMatches counts, per expired suppression, the actions it would
+	// still have matched.
+	expiredMatches map[*suppression]int
+}
+
+// newSuppressor returns a suppressor for c, which may be nil.
+func newSuppressor(c *config, now time.Time) *suppressor {
+	s := &suppressor{now: now, expiredMatches: make(map[*suppression]int)}
+	if c != nil {
+		s.suppressions = c.Suppressions
+	}
+	return s
+}
+
+// suppress returns the first active suppression matching d, or nil.
+func (s *suppressor) suppress(d *diffResult) *suppression {
+	for _, sup := range s.suppressions {
+		if !sup.matches(d) {
+			continue
+		}
+		if sup.expired(s.now) {
+			s.expiredMatches[sup]++
+			continue
+		}
+		return sup
+	}
+	return nil
+}
+
+// expired returns the expired suppressions, in config order.
+func (s *suppressor) expired() []*suppression {
+	var expired []*suppression
+	for _, sup := range s.suppressions {
+		if sup.expired(s.now) {
+			expired = append(expired, sup)
+		}
+	}
+	return expired
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "tools/execlog/proto"
+)
+
+// writeConfig writes a --config file and returns its path.
+func writeConfig(t *testing.T, dir, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob, path string
+		want       bool
+	}{
+		{"out/*.txt", "out/a.txt", true},
+		{"out/*.txt", "out/sub/a.txt", false},
+		{"out/**/*.txt", "out/a.txt", true},
+		{"out/**/*.txt", "out/sub/deeper/a.txt", true},
+		{"out/**", "out/sub/a.o", true},
+		{"**/stamp.h", "bazel-out/k8-fastbuild/bin/stamp.h", true},
+		{"out/a?.txt", "out/ab.txt", true},
+		{"out/a?.txt", "out/a/.txt", false},
+		{"out/a.txt", "out/aXtxt", false},
+	}
+	for _, tt := range tests {
+		if got := globRegexp(tt.glob).MatchString(tt.path); got != tt.want {
+			t.Errorf("glob %q on %q = %v, want %v", tt.glob, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no matcher":    `{"suppressions": [{"owner": "a", "reason": "r"}]}`,
+		"no owner":      `{"suppressions": [{"mnemonic": "Genrule", "reason": "r"}]}`,
+		"no reason":     `{"suppressions": [{"mnemonic": "Genrule", "owner": "a"}]}`,
+		"bad expiry":    `{"suppressions": [{"mnemonic": "Genrule", "owner": "a", "reason": "r", "expires": "next week"}]}`,
+		"unknown field": `{"suppressions": [{"mnemonc": "Genrule", "owner": "a", "reason": "r"}]}`,
+	}
+	for name, contents := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadConfig(writeConfig(t, t.TempDir(), contents)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestSuppression_Matches(t *testing.T) {
+	a := consumer("src/stamp.txt", "aaa", "out/stamp.h", "bbb")
+	a.TargetLabel = "//base/stamp:header"
+	a.EnvironmentVariables = []*pb.EnvironmentVariable{{Name: "BUILD_TIME", Value: "1"}}
+	b := consumer("src/stamp.txt", "xxx", "out/stamp.h", "yyy")
+	b.TargetLabel = a.TargetLabel
+	b.EnvironmentVariables = []*pb.EnvironmentVariable{{Name: "BUILD_TIME", Value: "2"}}
+	d := &diffResult{a: a, outlierExecs: []*pb.SpawnExec{b}}
+
+	tests := []struct {
+		name string
+		s    suppression
+		want bool
+	}{
+		{"mnemonic", suppression{Mnemonic: "Genrule"}, true},
+		{"other mnemonic", suppression{Mnemonic: "CppCompile"}, false},
+		{"package recursive", suppression{TargetLabel: "//base/..."}, true},
+		{"package recursive prefix only", suppression{TargetLabel: "//bas/..."}, false},
+		{"label glob", suppression{TargetLabel: "//base/stamp:*"}, true},
+		{"output", suppression{Output: "out/*.h"}, true},
+		{"environment variable", suppression{EnvironmentVariable: "BUILD_TIME"}, true},
+		{"unchanged environment variable", suppression{EnvironmentVariable: "PATH"}, false},
+		{"input", suppression{Input: "src/**"}, true},
+		{"all must match", suppression{Mnemonic: "Genrule", Output: "out/*.cc"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.s.Owner, tt.s.Reason = "owner", "reason"
+			if err := tt.s.compile(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.s.matches(d); got != tt.want {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_SuppressesAndReportsExpired(t *testing.T) {
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{
+		genrule("out/stamp.txt", "aaa"), genrule("out/vendor.bin", "aaa"), genrule("out/real.txt", "aaa"),
+	})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{
+		genrule("out/stamp.txt", "zzz"), genrule("out/vendor.bin", "zzz"), genrule("out/real.txt", "aaa"),
+	})
+	cfg := writeConfig(t, dir, `{
+  "suppressions": [
+    {"output": "out/stamp.txt", "owner": "build-team", "reason": "embeds the build time"},
+    {"output": "out/*.bin", "owner": "vendor-team", "reason": "vendor tool", "expires": "2026-03-31"}
+  ]
+}`)
+
+	run := func(now time.Time) (int, string) {
+		var code int
+		out := captureStdout(t, func() {
+			code = runWithOptions([]string{log1, log2}, options{configPath: cfg, now: now})
+		})
+		return code, out
+	}
+
+	code, out := run(time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC))
+	if code != exitDeterministic {
+		t.Errorf("before expiry: got exit code %d, want %d\n%s", code, exitDeterministic, out)
+	}
+	for _, want := range []string{
+		"Suppressed actions: 2",
+		"suppressed: embeds the build time (owner: build-team)",
+		"Summary: 3 paired actions compared, 0 non-deterministic (0 origin, 0 propagated), 2 suppressed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("before expiry: output missing %q:\n%s", want, out)
+		}
+	}
+
+	code, out = run(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	if code != exitNonDeterministic {
+		t.Errorf("after expiry: got exit code %d, want %d\n%s", code, exitNonDeterministic, out)
+	}
+	for _, want := range []string{
+		"Non-deterministic actions found: 1",
+		"Suppressed actions: 1",
+		"Expired suppressions: 1\n  output=out/*.bin (owner: vendor-team, expired 2026-03-31): vendor tool\n    would have suppressed 1 action(s)",
+		"1 non-deterministic (1 origin, 0 propagated), 1 suppressed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("after expiry: output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestConfig_Invalid_Exit2(t *testing.T) {
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{genrule("out/a.txt", "aaa")})
+	cfg := writeConfig(t, dir, `{"suppressions": [{"mnemonic": "Genrule"}]}`)
+	if code := runWithOptions([]string{log1, log1}, options{configPath: cfg}); code != exitUsageError {
+		t.Errorf("got exit code %d, want %d", code, exitUsageError)
+	}
+}
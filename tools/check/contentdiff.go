@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	pb "tools/execlog/proto"
+)
+
+// Limits on the content diffs printed for differing outputs.
+const (
+	// maxContentSize is the largest blob read for a content diff.
+	maxContentSize = 16 << 20
+	// maxContentDiffLines caps the diff lines printed per output.
+	maxContentDiffLines = 200
+	// diffContext is the number of unchanged lines around each hunk.
+	diffContext = 3
+)
+
+// blobStore looks up the content of output files.
+type blobStore interface {
+	// read returns the content of f as produced by the build that wrote the
+	// log with index log. The error wraps fs.ErrNotExist if the store does
+	// not have it.
+	read(log int, f *pb.File) ([]byte, error)
+	// name describes the store in messages, e.g. "disk cache".
+	name() string
+}
+
+var hexDigest = regexp.MustCompile(`^[0-9a-f]{2,}$`)
+
+// checkSize rejects files too large to diff.
+func checkSize(f *pb.File) error {
+	if size := f.Digest.GetSizeBytes(); size > maxContentSize {
+		return fmt.Errorf("%d bytes, larger than the %d byte limit", size, maxContentSize)
+	}
+	return nil
+}
+
+// diskCache reads blobs from a Bazel --disk_cache directory, which keeps
+// them in cas/<first two hex digits>/<hash>.
+type diskCache struct {
+	dir string
+}
+
+func (c diskCache) name() string { return "disk cache" }
+
+func (c diskCache) read(_ int, f *pb.File) ([]byte, error) {
+	hash := f.Digest.GetHash()
+	if !hexDigest.MatchString(hash) {
+		return nil, fmt.Errorf("invalid digest %q: %w", hash, fs.ErrNotExist)
+	}
+	if err := checkSize(f); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Join(c.dir, "cas", hash[:2], hash))
+}
+
+// outputTree reads outputs from copies of the execroot of each build, one
+// root per log. Files whose digest no longer matches the log, for example
+// because a later build overwrote them, count as missing.
+type outputTree struct {
+	roots []string
+}
+
+func (t outputTree) name() string { return "output tree" }
+
+func (t outputTree) read(log int, f *pb.File) ([]byte, error) {
+	if log >= len(t.roots) || !filepath.IsLocal(f.Path) {
+		return nil, fmt.Errorf("%s: %w", f.Path, fs.ErrNotExist)
+	}
+	if err := checkSize(f); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(t.roots[log], f.Path))
+	if err != nil {
+		return nil, err
+	}
+	if !digestMatches(f.Digest, data) {
+		return nil, fmt.Errorf("%s in %s was overwritten: %w", f.Path, t.roots[log], fs.ErrNotExist)
+	}
+	return data, nil
+}
+
+// digestMatches reports whether data has digest d. Digests in hash functions
+// this tool cannot compute are assumed to match.
+func digestMatches(d *pb.Digest, data []byte) bool {
+	if d.GetHash() == "" {
+		return true
+	}
+	var sum []byte
+	switch strings.ToUpper(d.HashFunctionName) {
+	case "", "SHA-256", "SHA256":
+		s := sha256.Sum256(data)
+		sum = s[:]
+	case "SHA-1", "SHA1":
+		s := sha1.Sum(data)
+		sum = s[:]
+	case "MD5":
+		s := md5.Sum(data)
+		sum = s[:]
+	default:
+		return true
+	}
+	return hex.EncodeToString(sum) == d.Hash
+}
+
+// multiStore tries each store in turn.
+type multiStore []blobStore
+
+func (m multiStore) name() string {
+	names := make([]string, len(m))
+	for i, s := range m {
+		names[i] = s.name()
+	}
+	return strings.Join(names, " or ")
+}
+
+func (m multiStore) read(log int, f *pb.File) ([]byte, error) {
+	err := fmt.Errorf("%s: %w", f.Path, fs.ErrNotExist)
+	for _, s := range m {
+		var data []byte
+		data, err = s.read(log, f)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return data, err
+		}
+	}
+	return nil, err
+}
+
+// isText reports whether data looks like text: valid UTF-8 without NUL bytes.
+func isText(data []byte) bool {
+	return bytes.IndexByte(data, 0) < 0 && utf8.Valid(data)
+}
+
+// splitLines splits text into lines without their trailing newlines.
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// contentDiff returns detail lines describing how the content of one output
+// differs between the version fa written by log aLog and the version fb
+// written by log bLog. Archives are compared member by member.
+func contentDiff(store blobStore, aLog int, fa *pb.File, bLog int, fb *pb.File) []string {
+	a, errA := store.read(aLog, fa)
+	b, errB := store.read(bLog, fb)
+	var missing []string
+	for _, r := range []struct {
+		f   *pb.File
+		err error
+	}{{fa, errA}, {fb, errB}} {
+		switch {
+		case r.err == nil:
+		case errors.Is(r.err, fs.ErrNotExist):
+			missing = append(missing, fmt.Sprintf("(%s not in %s)", r.f.Digest.GetHash(), store.name()))
+		default:
+			missing = append(missing, fmt.Sprintf("(%s: %v)", r.f.Digest.GetHash(), r.err))
+		}
+	}
+	if len(missing) > 0 {
+		return missing
+	}
+
+	var lines []string
+	if format := archiveFormat(fa.Path, a); format != "" && format == archiveFormat(fb.Path, b) {
+		lines = archiveDiff(format, a, b)
+	} else if isELF(a) && isELF(b) {
+		lines = elfDiff(a, b)
+	} else if isText(a) && isText(b) {
+		lines = unifiedDiff(splitLines(a), splitLines(b), diffContext)
+		if len(lines) == 0 {
+			lines = []string{"(only the trailing newline differs)"}
+		}
+	} else {
+		lines = binarySummary(a, b)
+	}
+	if len(lines) > maxContentDiffLines {
+		lines = append(lines[:maxContentDiffLines], fmt.Sprintf("... (%d more lines)", len(lines)-maxContentDiffLines))
+	}
+	return lines
+}
+
+// binarySummary describes how two binary blobs differ: their sizes, the
+// ranges of differing bytes and a hex dump around the first difference.
+func binarySummary(a, b []byte) []string {
+	lines := []string{fmt.Sprintf("binary content differs: %d -> %d bytes", len(a), len(b))}
+
+	type span struct{ start, end int }
+	var spans []span
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		if len(spans) > 0 && spans[len(spans)-1].end == i {
+			spans[len(spans)-1].end++
+		} else {
+			spans = append(spans, span{i, i + 1})
+		}
+	}
+	if len(a) != len(b) {
+		spans = append(spans, span{n, max(len(a), len(b))})
+	}
+	if len(spans) == 0 {
+		return lines
+	}
+
+	const maxSpans = 8
+	var ranges []string
+	for i, s := range spans {
+		if i == maxSpans {
+			ranges = append(ranges, fmt.Sprintf("%d more", len(spans)-maxSpans))
+			break
+		}
+		ranges = append(ranges, fmt.Sprintf("0x%x-0x%x", s.start, s.end-1))
+	}
+	lines = append(lines, fmt.Sprintf("differing byte ranges: %s", strings.Join(ranges, ", ")))
+
+	offset := spans[0].start &^ 0xf
+	lines = append(lines, "- "+hexLine(a, offset), "+ "+hexLine(b, offset))
+	return lines
+}
+
+// hexLine dumps the 16 bytes of data at offset, like hexdump -C.
+func hexLine(data []byte, offset int) string {
+	var hex, ascii strings.Builder
+	for i := offset; i < offset+16; i++ {
+		if i >= len(data) {
+			hex.WriteString("   ")
+			continue
+		}
+		fmt.Fprintf(&hex, "%02x ", data[i])
+		if c := data[i]; c >= 0x20 && c < 0x7f {
+			ascii.WriteByte(c)
+		} else {
+			ascii.WriteByte('.')
+		}
+	}
+	return fmt.Sprintf("%08x  %s |%s|", offset, hex.String(), ascii.String())
+}
+
+// outputContentDiffs returns, for every actual output whose digest differs
+// between a (from log aLog) and b (from log bLog), a header line followed by
+// its indented content diff.
+func outputContentDiffs(store blobStore, aLog int, a *pb.SpawnExec, bLog int, b *pb.SpawnExec) []string {
+	aFiles := make(map[string]*pb.File)
+	for _, f := range a.ActualOutputs {
+		aFiles[f.Path] = f
+	}
+	bFiles := make(map[string]*pb.File)
+	for _, f := range b.ActualOutputs {
+		bFiles[f.Path] = f
+	}
+
+	var lines []string
+	for _, path := range changedPaths(a.ActualOutputs, b.ActualOutputs) {
+		fa, okA := aFiles[path]
+		fb, okB := bFiles[path]
+		if !okA || !okB || fa.Digest == nil || fb.Digest == nil {
+			continue
+		}
+		lines = append(lines, path+":")
+		for _, line := range contentDiff(store, aLog, fa, bLog, fb) {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+// writeBlob stores content in the disk cache at dir and returns its digest.
+func writeBlob(t *testing.T, dir, content string) *pb.Digest {
+	t.Helper()
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+	path := filepath.Join(dir, "cas", hash[:2], hash)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return &pb.Digest{Hash: hash, SizeBytes: int64(len(content))}
+}
+
+// outputFile returns an output file with digest d.
+func outputFile(d *pb.Digest) *pb.File {
+	return &pb.File{Path: "out/f", Digest: d}
+}
+
+func TestContentDiff_Text(t *testing.T) {
+	cache := t.TempDir()
+	da := writeBlob(t, cache, "package main\n\nconst built = \"Mon\"\n")
+	db := writeBlob(t, cache, "package main\n\nconst built = \"Tue\"\n")
+
+	got := strings.Join(contentDiff(diskCache{dir: cache}, 0, outputFile(da), 1, outputFile(db)), "\n")
+	want := "@@ -1,3 +1,3 @@\n package main\n \n-const built = \"Mon\"\n+const built = \"Tue\""
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestContentDiff_Binary(t *testing.T) {
+	cache := t.TempDir()
+	da := writeBlob(t, cache, "\x7fELF\x02\x01\x01\x00stamp=0001\x00tail")
+	db := writeBlob(t, cache, "\x7fELF\x02\x01\x01\x00stamp=0002\x00tail!")
+
+	got := contentDiff(diskCache{dir: cache}, 0, outputFile(da), 1, outputFile(db))
+	joined := strings.Join(got, "\n")
+	for _, want := range []string{
+		"(cannot read ELF file: EOF)",
+		"binary content differs: 23 -> 24 bytes",
+		"differing byte ranges: 0x11-0x11, 0x17-0x17",
+		"- 00000010  30 31 00 74 61 69 6c ",
+		"+ 00000010  30 32 00 74 61 69 6c 21 ",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing %q in:\n%s", want, joined)
+		}
+	}
+}
+
+func TestContentDiff_Missing(t *testing.T) {
+	cache := t.TempDir()
+	da := writeBlob(t, cache, "present\n")
+	db := &pb.Digest{Hash: strings.Repeat("ab", 32), SizeBytes: 3}
+
+	got := contentDiff(diskCache{dir: cache}, 0, outputFile(da), 1, outputFile(db))
+	if len(got) != 1 || got[0] != "("+db.Hash+" not in disk cache)" {
+		t.Errorf("got %q, want a missing blob note", got)
+	}
+
+	bad := &pb.Digest{Hash: "../../etc/passwd"}
+	if got := contentDiff(diskCache{dir: cache}, 0, outputFile(da), 1, outputFile(bad)); len(got) != 1 || !strings.Contains(got[0], "not in disk cache") {
+		t.Errorf("invalid digest: got %q", got)
+	}
+}
+
+func TestDiskCache_ShowsContentDiff(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "cache")
+	a := genrule("out/version.h", "")
+	a.ActualOutputs[0].Digest = writeBlob(t, cache, "#define BUILD 1\n")
+	b := genrule("out/version.h", "")
+	b.ActualOutputs[0].Digest = writeBlob(t, cache, "#define BUILD 2\n")
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{a})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{b})
+
+	out := captureStdout(t, func() {
+		runWithOptions([]string{log1, log2}, options{diskCache: cache})
+	})
+	want := "    content (from disk cache):\n      out/version.h:\n        @@ -1 +1 @@\n        -#define BUILD 1\n        +#define BUILD 2\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("output missing %q:\n%s", want, out)
+	}
+
+	if code := runWithOptions([]string{log1, log2}, options{diskCache: filepath.Join(dir, "nope")}); code != exitUsageError {
+		t.Errorf("missing disk cache: got exit code %d, want %d", code, exitUsageError)
+	}
+}
+
+func TestDiskCache_NoContentHeaderWithoutDiffs(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "cache")
+	a := genrule("out/a.txt", "")
+	a.ActualOutputs[0].Digest = writeBlob(t, cache, "same\n")
+	b := genrule("out/a.txt", "")
+	b.ActualOutputs[0].Digest = a.ActualOutputs[0].Digest
+	// Only an extra output differs, which has no counterpart to diff against.
+	b.ActualOutputs = append(b.ActualOutputs, &pb.File{Path: "out/extra.txt", Digest: writeBlob(t, cache, "extra\n")})
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{a})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{b})
+
+	out := captureStdout(t, func() {
+		runWithOptions([]string{log1, log2}, options{diskCache: cache})
+	})
+	if !strings.Contains(out, "actual_outputs") {
+		t.Fatalf("expected actual_outputs to differ:\n%s", out)
+	}
+	if strings.Contains(out, "content (from") {
+		t.Errorf("content header printed without content diffs:\n%s", out)
+	}
+}
+
+func TestOutputTree(t *testing.T) {
+	dir := t.TempDir()
+	roots := []string{filepath.Join(dir, "build1"), filepath.Join(dir, "build2")}
+	for i, content := range []string{"one\n", "two\n"} {
+		if err := os.MkdirAll(filepath.Join(roots[i], "out"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(roots[i], "out", "f"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache := t.TempDir()
+	fa := outputFile(writeBlob(t, cache, "one\n"))
+	fb := outputFile(writeBlob(t, cache, "two\n"))
+	tree := outputTree{roots: roots}
+
+	got := strings.Join(contentDiff(tree, 0, fa, 1, fb), "\n")
+	if got != "@@ -1 +1 @@\n-one\n+two" {
+		t.Errorf("got:\n%s", got)
+	}
+
+	// The first build's file was overwritten, so its digest no longer matches.
+	got = strings.Join(contentDiff(tree, 1, fa, 1, fb), "\n")
+	if !strings.Contains(got, "not in output tree") {
+		t.Errorf("expected overwritten output to be missing, got:\n%s", got)
+	}
+
+	escaping := &pb.File{Path: "../build2/out/f", Digest: fb.Digest}
+	if _, err := tree.read(0, escaping); err == nil {
+		t.Error("expected paths outside the output tree to be rejected")
+	}
+}
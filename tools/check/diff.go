@@ -0,0 +1,184 @@
+package main
+
+import "fmt"
+
+// editOp is the kind of a single step in an edit script.
+type editOp int
+
+const (
+	opEqual editOp = iota
+	opDelete
+	opInsert
+)
+
+// edit is one step of an edit script turning a into b. aIndex is valid for
+// opEqual and opDelete, bIndex for opEqual and opInsert.
+type edit struct {
+	op     editOp
+	aIndex int
+	bIndex int
+}
+
+// maxEditDistance bounds the work done by diffStrings. Its memory use grows
+// with the square of the edit distance.
+const maxEditDistance = 2000
+
+// diffStrings returns a shortest edit script turning a into b, computed with
+// Myers' O(ND) algorithm. It returns false if a and b differ in more than
+// maxEditDistance elements.
+func diffStrings(a, b []string) ([]edit, bool) {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD > maxEditDistance {
+		maxD = maxEditDistance
+	}
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	// trace[d] holds v[k] for k in [-d, d] after round d.
+	var trace [][]int
+	get := func(d, k int) int { return trace[d][k+d] }
+
+	for d := 0; d <= maxD; d++ {
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+		if done {
+			return backtrack(n, m, d, get), true
+		}
+	}
+	return nil, false
+}
+
+// backtrack walks the trace of diffStrings back from (n, m) at distance d.
+func backtrack(n, m, d int, get func(d, k int) int) []edit {
+	var edits []edit
+	x, y := n, m
+	for ; d > 0; d-- {
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && get(d-1, k-1) < get(d-1, k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := get(d-1, prevK)
+		prevY := prevX - prevK
+		startX, startY := prevX, prevY+1
+		if prevK == k-1 {
+			startX, startY = prevX+1, prevY
+		}
+		for x > startX && y > startY {
+			x--
+			y--
+			edits = append(edits, edit{op: opEqual, aIndex: x, bIndex: y})
+		}
+		if prevK == k+1 {
+			y--
+			edits = append(edits, edit{op: opInsert, aIndex: -1, bIndex: y})
+		} else {
+			x--
+			edits = append(edits, edit{op: opDelete, aIndex: x, bIndex: -1})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, edit{op: opEqual, aIndex: x, bIndex: y})
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// unifiedDiff formats the differences between the lines a and b as unified
+// diff hunks with the given number of context lines. It returns nil if the
+// lines are equal, and a single explanatory line if they differ too much.
+func unifiedDiff(a, b []string, context int) []string {
+	edits, ok := diffStrings(a, b)
+	if !ok {
+		return []string{fmt.Sprintf("(more than %d lines differ, diff not shown)", maxEditDistance)}
+	}
+
+	// aBefore[i] and bBefore[i] count the lines of a and b before edits[i].
+	aBefore := make([]int, len(edits)+1)
+	bBefore := make([]int, len(edits)+1)
+	for i, e := range edits {
+		aBefore[i+1], bBefore[i+1] = aBefore[i], bBefore[i]
+		if e.op != opInsert {
+			aBefore[i+1]++
+		}
+		if e.op != opDelete {
+			bBefore[i+1]++
+		}
+	}
+
+	var lines []string
+	for start := 0; start < len(edits); {
+		// Find the next change and extend the hunk while changes are at
+		// most 2*context equal lines apart.
+		for start < len(edits) && edits[start].op == opEqual {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		end := start
+		for i := start; i < len(edits); i++ {
+			if edits[i].op != opEqual {
+				end = i + 1
+			} else if i-end >= 2*context {
+				break
+			}
+		}
+		from := max(start-context, 0)
+		to := min(end+context, len(edits))
+
+		lines = append(lines, fmt.Sprintf("@@ -%s +%s @@",
+			hunkRange(aBefore[from], aBefore[to]-aBefore[from]),
+			hunkRange(bBefore[from], bBefore[to]-bBefore[from])))
+		for _, e := range edits[from:to] {
+			switch e.op {
+			case opEqual:
+				lines = append(lines, " "+a[e.aIndex])
+			case opDelete:
+				lines = append(lines, "-"+a[e.aIndex])
+			case opInsert:
+				lines = append(lines, "+"+b[e.bIndex])
+			}
+		}
+		start = to
+	}
+	return lines
+}
+
+// hunkRange formats one side of a hunk header from the number of lines
+// before the hunk and the number of lines in it, numbered as diff -u does.
+func hunkRange(before, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, length)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffStrings(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"", ""},
+		{"a b c", "a b c"},
+		{"a b c", "a x c"},
+		{"", "a b"},
+		{"a b", ""},
+		{"a b c d e f", "b c e f g"},
+		{"x a b c", "a b c x"},
+	}
+	for _, tt := range tests {
+		a, b := strings.Fields(tt.a), strings.Fields(tt.b)
+		edits, ok := diffStrings(a, b)
+		if !ok {
+			t.Fatalf("diffStrings(%q, %q) gave up", tt.a, tt.b)
+		}
+		// Replaying the edit script must produce b from a.
+		var gotA, gotB []string
+		for _, e := range edits {
+			switch e.op {
+			case opEqual:
+				if a[e.aIndex] != b[e.bIndex] {
+					t.Errorf("%q -> %q: equal edit pairs %q with %q", tt.a, tt.b, a[e.aIndex], b[e.bIndex])
+				}
+				gotA = append(gotA, a[e.aIndex])
+				gotB = append(gotB, b[e.bIndex])
+			case opDelete:
+				gotA = append(gotA, a[e.aIndex])
+			case opInsert:
+				gotB = append(gotB, b[e.bIndex])
+			}
+		}
+		if strings.Join(gotA, " ") != tt.a || strings.Join(gotB, " ") != tt.b {
+			t.Errorf("%q -> %q: edit script replays to %q -> %q", tt.a, tt.b, gotA, gotB)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := strings.Fields("1 2 3 4 5 6 7 8 9 10 11 12 13 14")
+	b := strings.Fields("1 2 3 4 5 six 7 8 9 10 11 12 13 14 15")
+	want := []string{
+		"@@ -3,7 +3,7 @@",
+		" 3", " 4", " 5", "-6", "+six", " 7", " 8", " 9",
+		"@@ -12,3 +12,4 @@",
+		" 12", " 13", " 14", "+15",
+	}
+	got := unifiedDiff(a, b, 3)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Changes at most 2*context lines apart share a hunk, as in diff -u.
+	b = strings.Fields("1 2 3 4 5 six 7 8 9 10 11 12 thirteen 14")
+	if got := unifiedDiff(a, b, 3); len(got) == 0 || got[0] != "@@ -3,12 +3,12 @@" || strings.Count(strings.Join(got, "\n"), "@@ -") != 1 {
+		t.Errorf("expected a single merged hunk, got:\n%s", strings.Join(got, "\n"))
+	}
+
+	if got := unifiedDiff(a, a, 3); got != nil {
+		t.Errorf("equal input: got %q, want nil", got)
+	}
+	if got := unifiedDiff(nil, []string{"new"}, 3); strings.Join(got, "\n") != "@@ -0,0 +1 @@\n+new" {
+		t.Errorf("new file: got %q", got)
+	}
+}
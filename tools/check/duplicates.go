@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	pb "tools/execlog/proto"
+)
+
+// Ways to tell apart actions that share a key, in order of preference.
+const (
+	pairedByMnemonic   = "mnemonic"
+	pairedByTarget     = "target"
+	pairedByOutputs    = "outputs"
+	pairedByOccurrence = "occurrence"
+)
+
+// discriminators extract a stable property that may tell apart actions
+// sharing a key, such as a compile and a header check of the same file.
+var discriminators = []struct {
+	name  string
+	value func(*pb.SpawnExec) string
+}{
+	{pairedByMnemonic, func(e *pb.SpawnExec) string { return e.Mnemonic }},
+	{pairedByTarget, func(e *pb.SpawnExec) string { return e.TargetLabel }},
+	{pairedByOutputs, func(e *pb.SpawnExec) string { return strings.Join(e.ListedOutputs, ",") }},
+}
+
+// keySeparator joins a shared key and the value that tells its actions
+// apart into a composite key. Paths cannot contain NUL, so composite keys
+// never collide with the key of another action, such as an output named
+// "out/dup#A", and sort right after the shared key. displayKey renders it
+// as "#".
+const keySeparator = "\x00"
+
+// displayKey returns key as shown in reports, with composite keys written
+// "key#value".
+func displayKey(key string) string {
+	return strings.ReplaceAll(key, keySeparator, "#")
+}
+
+// displayKeys applies displayKey to every key.
+func displayKeys(keys []string) []string {
+	for i, key := range keys {
+		keys[i] = displayKey(key)
+	}
+	return keys
+}
+
+// duplicateKey is an action key shared by several actions of one log, for
+// example by test retries or by builds of one file in two configurations.
+type duplicateKey struct {
+	key string
+	// counts holds the number of actions with the key in each log.
+	counts []int
+	// pairedBy is the discriminator that told the actions apart, or
+	// pairedByOccurrence if they were paired in log order.
+	pairedBy string
+}
+
+// distinct reports whether value tells apart the actions of every log.
+func distinct(perLog [][]*pb.SpawnExec, value func(*pb.SpawnExec) string) bool {
+	for _, execs := range perLog {
+		seen := make(map[string]bool)
+		for _, exec := range execs {
+			v := value(exec)
+			if seen[v] {
+				return false
+			}
+			seen[v] = true
+		}
+	}
+	return true
+}
+
+// resolveDuplicates turns the actions of each log, grouped by key, into one
+// action per key. Actions whose key is shared within any log are keyed as
+// "key#value" in every log, with keySeparator for "#", by the first
+// discriminator that tells them apart everywhere, or as "key#n" for the nth
+// occurrence in the log.
+func resolveDuplicates(logs []map[string][]*pb.SpawnExec) ([]map[string]*pb.SpawnExec, []duplicateKey) {
+	resolved := make([]map[string]*pb.SpawnExec, len(logs))
+	duplicated := make(map[string]bool)
+	for i, actions := range logs {
+		resolved[i] = make(map[string]*pb.SpawnExec, len(actions))
+		for key, execs := range actions {
+			if len(execs) > 1 {
+				duplicated[key] = true
+			} else {
+				resolved[i][key] = execs[0]
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(duplicated))
+	for key := range duplicated {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var duplicates []duplicateKey
+	for _, key := range keys {
+		dup := duplicateKey{key: key, counts: make([]int, len(logs)), pairedBy: pairedByOccurrence}
+		perLog := make([][]*pb.SpawnExec, len(logs))
+		for i, actions := range logs {
+			perLog[i] = actions[key]
+			dup.counts[i] = len(perLog[i])
+			delete(resolved[i], key)
+		}
+		var value func(*pb.SpawnExec) string
+		for _, d := range discriminators {
+			if distinct(perLog, d.value) {
+				dup.pairedBy, value = d.name, d.value
+				break
+			}
+		}
+		for i, execs := range perLog {
+			for n, exec := range execs {
+				suffix := fmt.Sprint(n + 1)
+				if value != nil {
+					suffix = value(exec)
+				}
+				resolved[i][key+keySeparator+suffix] = exec
+			}
+		}
+		duplicates = append(duplicates, dup)
+	}
+	return resolved, duplicates
+}
+
+// formatDuplicate describes a duplicate key for the text report, e.g.
+// "out/a.o: 2 in log1, 2 in log2 (paired by mnemonic)".
+func formatDuplicate(dup duplicateKey) string {
+	var counts []string
+	for i, n := range dup.counts {
+		if n > 0 {
+			counts = append(counts, fmt.Sprintf("%d in log%d", n, i+1))
+		}
+	}
+	return fmt.Sprintf("%s: %s (paired by %s)", dup.key, strings.Join(counts, ", "), dup.pairedBy)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestResolveDuplicates(t *testing.T) {
+	compile := genrule("out/a.o", "aaa")
+	compile.Mnemonic = "CppCompile"
+	check := genrule("out/a.o", "bbb")
+	check.Mnemonic = "CppHeaderCheck"
+	retry1 := genrule("out/test.log", "ccc")
+	retry2 := genrule("out/test.log", "ddd")
+	other := genrule("out/b.txt", "eee")
+
+	logs := []map[string][]*pb.SpawnExec{
+		{
+			"out/a.o":      {compile, check},
+			"out/test.log": {retry1, retry2},
+			"out/b.txt":    {other},
+		},
+		{
+			"out/a.o":      {compile},
+			"out/test.log": {retry1},
+			"out/b.txt":    {other},
+		},
+	}
+	resolved, duplicates := resolveDuplicates(logs)
+
+	var keys []string
+	for key := range resolved[0] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	want := []string{"out/a.o#CppCompile", "out/a.o#CppHeaderCheck", "out/b.txt", "out/test.log#1", "out/test.log#2"}
+	if !reflect.DeepEqual(displayKeys(keys), want) {
+		t.Errorf("log1 keys = %q, want %q", keys, want)
+	}
+	if resolved[1]["out/a.o"+keySeparator+"CppCompile"] != compile || resolved[1]["out/test.log"+keySeparator+"1"] != retry1 {
+		t.Errorf("expected log2 keyed consistently, got %v", resolved[1])
+	}
+
+	wantDups := []duplicateKey{
+		{key: "out/a.o", counts: []int{2, 1}, pairedBy: pairedByMnemonic},
+		{key: "out/test.log", counts: []int{2, 1}, pairedBy: pairedByOccurrence},
+	}
+	if !reflect.DeepEqual(duplicates, wantDups) {
+		t.Errorf("duplicates = %+v, want %+v", duplicates, wantDups)
+	}
+}
+
+func TestDuplicateKeys_Reported(t *testing.T) {
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{
+		genrule("out/test.log", "aaa"),
+		genrule("out/test.log", "bbb"),
+	})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{
+		genrule("out/test.log", "aaa"),
+		genrule("out/test.log", "ccc"),
+	})
+
+	var code int
+	out := captureStdout(t, func() {
+		code = run([]string{log1, log2}, "", false)
+	})
+	if code != exitNonDeterministic {
+		t.Errorf("got exit code %d, want %d", code, exitNonDeterministic)
+	}
+	for _, want := range []string{
+		"  out/test.log#2 [Genrule]\n",
+		"Duplicate action keys: 1\n  out/test.log: 2 in log1, 2 in log2 (paired by occurrence)\n",
+		"Summary: 2 paired actions compared, 1 non-deterministic",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDuplicateKeys_DoNotCollide(t *testing.T) {
+	dir := t.TempDir()
+	action := func(output, mnemonic, hash string) *pb.SpawnExec {
+		exec := genrule(output, hash)
+		exec.Mnemonic = mnemonic
+		return exec
+	}
+	// The duplicates of out/dup are told apart by mnemonic, as "out/dup#A"
+	// and "out/dup#B", which is also the key of another action.
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{
+		action("out/dup", "A", "aaa"),
+		action("out/dup", "B", "bbb"),
+		action("out/dup#A", "C", "ccc"),
+	})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{
+		action("out/dup", "A", "aaa"),
+		action("out/dup", "B", "bbb"),
+		action("out/dup#A", "C", "xxx"),
+	})
+
+	for _, streaming := range []bool{false, true} {
+		out := captureStdout(t, func() {
+			runWithOptions([]string{log1, log2}, options{streaming: streaming})
+		})
+		for _, want := range []string{
+			"Non-deterministic actions found: 1 ",
+			"  out/dup#A [C]\n",
+			"Summary: 3 paired actions compared, 1 non-deterministic",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("streaming=%v: output missing %q:\n%s", streaming, want, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Limits on the ELF section details printed per output.
+const (
+	// minStringLength is the shortest printable run reported as a string,
+	// as with strings(1).
+	minStringLength = 4
+	// maxSectionStrings caps the strings listed per side of a section.
+	maxSectionStrings = 10
+)
+
+// elfMagic starts every ELF file.
+var elfMagic = []byte("\x7fELF")
+
+// isELF reports whether data looks like an ELF binary or object file.
+func isELF(data []byte) bool {
+	return bytes.HasPrefix(data, elfMagic)
+}
+
+// elfSection is the content of one section, keyed by name.
+type elfSection struct {
+	key     string
+	section *elf.Section
+	data    []byte
+}
+
+// elfSections returns the sections of f in file order. Repeated names, as in
+// relocatable objects, get a "#n" suffix.
+func elfSections(f *elf.File) ([]elfSection, error) {
+	seen := make(map[string]int)
+	var sections []elfSection
+	for _, s := range f.Sections {
+		if s.Type == elf.SHT_NULL {
+			continue
+		}
+		seen[s.Name]++
+		key := s.Name
+		if n := seen[s.Name]; n > 1 {
+			key = fmt.Sprintf("%s#%d", s.Name, n)
+		}
+		var data []byte
+		if s.Type != elf.SHT_NOBITS {
+			var err error
+			if data, err = s.Data(); err != nil {
+				return nil, fmt.Errorf("section %s: %w", s.Name, err)
+			}
+		}
+		sections = append(sections, elfSection{key: key, section: s, data: data})
+	}
+	return sections, nil
+}
+
+// elfDiff compares two ELF files section by section and returns detail
+// lines naming the sections that differ. Build IDs are decoded, and the
+// printable strings that differ are listed, which is usually enough to spot
+// an embedded path or timestamp.
+func elfDiff(a, b []byte) []string {
+	fa, sa, err := readELF(a)
+	if err == nil {
+		var fb *elf.File
+		var sb []elfSection
+		if fb, sb, err = readELF(b); err == nil {
+			return elfSectionDiff(fa, sa, fb, sb)
+		}
+	}
+	return append([]string{fmt.Sprintf("(cannot read ELF file: %v)", err)}, binarySummary(a, b)...)
+}
+
+// readELF parses data as an ELF file and reads its sections.
+func readELF(data []byte) (*elf.File, []elfSection, error) {
+	f, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	sections, err := elfSections(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, sections, nil
+}
+
+// elfSectionDiff compares the parsed ELF files fa and fb.
+func elfSectionDiff(fa *elf.File, sa []elfSection, fb *elf.File, sb []elfSection) []string {
+	lines := []string{fmt.Sprintf("ELF %s %s: %d -> %d sections", fa.Type, fa.Machine, len(sa), len(sb))}
+	if fa.Type != fb.Type || fa.Machine != fb.Machine || fa.Class != fb.Class {
+		lines = append(lines, fmt.Sprintf("header: %s %s %s -> %s %s %s", fa.Class, fa.Type, fa.Machine, fb.Class, fb.Type, fb.Machine))
+	}
+	if fa.Entry != fb.Entry {
+		lines = append(lines, fmt.Sprintf("entry point: 0x%x -> 0x%x", fa.Entry, fb.Entry))
+	}
+
+	byKey := make(map[string]elfSection)
+	for _, s := range sb {
+		byKey[s.key] = s
+	}
+	inA := make(map[string]bool)
+	identical := 0
+	for _, x := range sa {
+		inA[x.key] = true
+		y, ok := byKey[x.key]
+		if !ok {
+			lines = append(lines, fmt.Sprintf("removed section: %s (%d bytes)", x.key, x.section.Size))
+			continue
+		}
+		if bytes.Equal(x.data, y.data) && x.section.Size == y.section.Size {
+			identical++
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("section %s differs (%d -> %d bytes)", x.key, x.section.Size, y.section.Size))
+		for _, line := range sectionDiff(x, y, fa.ByteOrder, fb.ByteOrder) {
+			lines = append(lines, "  "+line)
+		}
+	}
+	for _, y := range sb {
+		if !inA[y.key] {
+			lines = append(lines, fmt.Sprintf("added section: %s (%d bytes)", y.key, y.section.Size))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("%d section(s) identical", identical))
+	return lines
+}
+
+// sectionDiff describes how the content of one section changed.
+func sectionDiff(a, b elfSection, orderA, orderB binary.ByteOrder) []string {
+	if a.section.Type == elf.SHT_NOTE {
+		na, nb := elfNotes(a.data, orderA), elfNotes(b.data, orderB)
+		if len(na) > 0 || len(nb) > 0 {
+			var lines []string
+			for _, n := range na {
+				lines = append(lines, "- "+n)
+			}
+			for _, n := range nb {
+				lines = append(lines, "+ "+n)
+			}
+			return lines
+		}
+	}
+
+	removed, added := stringsDiff(printableStrings(a.data), printableStrings(b.data))
+	if len(removed) > 0 || len(added) > 0 {
+		var lines []string
+		lines = append(lines, limitStrings("-", removed)...)
+		lines = append(lines, limitStrings("+", added)...)
+		return lines
+	}
+
+	n := min(len(a.data), len(b.data))
+	for i := 0; i < n; i++ {
+		if a.data[i] != b.data[i] {
+			return []string{fmt.Sprintf("first difference at section offset 0x%x", i)}
+		}
+	}
+	return nil
+}
+
+// elfNotes decodes the entries of a note section as "name type: desc", with
+// desc in hex except for Go build IDs, which are text.
+func elfNotes(data []byte, order binary.ByteOrder) []string {
+	var notes []string
+	// Sizes are widened to uint64, so that aligning a corrupt size near
+	// 2^32 cannot wrap around.
+	align4 := func(n uint64) uint64 { return (n + 3) &^ 3 }
+	for len(data) >= 12 {
+		namesz, descsz, typ := uint64(order.Uint32(data[0:4])), uint64(order.Uint32(data[4:8])), order.Uint32(data[8:12])
+		data = data[12:]
+		if namesz > uint64(len(data)) || descsz > uint64(len(data)) || align4(namesz)+align4(descsz) > uint64(len(data)) {
+			break
+		}
+		name := strings.TrimRight(string(data[:namesz]), "\x00")
+		desc := data[align4(namesz) : align4(namesz)+descsz]
+		data = data[align4(namesz)+align4(descsz):]
+
+		value := hex.EncodeToString(desc)
+		if name == "Go" {
+			value = fmt.Sprintf("%q", strings.TrimRight(string(desc), "\x00"))
+		}
+		label := fmt.Sprintf("%s type %d", name, typ)
+		switch {
+		case name == "GNU" && typ == 3:
+			label = "GNU build-id"
+		case name == "Go" && typ == 4:
+			label = "Go build ID"
+		}
+		notes = append(notes, fmt.Sprintf("%s: %s", label, value))
+	}
+	return notes
+}
+
+// printableStrings returns the runs of at least minStringLength printable
+// ASCII characters in data, in order.
+func printableStrings(data []byte) []string {
+	var result []string
+	start := -1
+	for i := 0; i <= len(data); i++ {
+		printable := i < len(data) && (data[i] >= 0x20 && data[i] < 0x7f || data[i] == '\t')
+		if printable {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= minStringLength {
+			result = append(result, string(data[start:i]))
+		}
+		start = -1
+	}
+	return result
+}
+
+// stringsDiff returns the strings only in a and those only in b, counting
+// repetitions, each in their original order.
+func stringsDiff(a, b []string) (removed, added []string) {
+	counts := make(map[string]int)
+	for _, s := range b {
+		counts[s]++
+	}
+	for _, s := range a {
+		if counts[s] > 0 {
+			counts[s]--
+		} else {
+			removed = append(removed, s)
+		}
+	}
+	counts = make(map[string]int)
+	for _, s := range a {
+		counts[s]++
+	}
+	for _, s := range b {
+		if counts[s] > 0 {
+			counts[s]--
+		} else {
+			added = append(added, s)
+		}
+	}
+	return removed, added
+}
+
+// limitStrings formats up to maxSectionStrings strings with prefix.
+func limitStrings(prefix string, strs []string) []string {
+	var lines []string
+	for i, s := range strs {
+		if i == maxSectionStrings {
+			lines = append(lines, fmt.Sprintf("%s ... (%d more)", prefix, len(strs)-maxSectionStrings))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s %q", prefix, s))
+	}
+	return lines
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+type testSection struct {
+	name string
+	typ  elf.SectionType
+	data []byte
+}
+
+// makeELF builds a little-endian ELF64 relocatable file with the given
+// sections and a section name table.
+func makeELF(sections []testSection) []byte {
+	const ehdrSize, shdrSize = 64, 64
+	shstrtab := []byte{0}
+	nameOffsets := make([]uint32, len(sections)+1)
+	for i, s := range sections {
+		nameOffsets[i] = uint32(len(shstrtab))
+		shstrtab = append(append(shstrtab, s.name...), 0)
+	}
+	nameOffsets[len(sections)] = uint32(len(shstrtab))
+	shstrtab = append(append(shstrtab, ".shstrtab"...), 0)
+	all := append(append([]testSection{}, sections...), testSection{".shstrtab", elf.SHT_STRTAB, shstrtab})
+
+	var body bytes.Buffer
+	offsets := make([]uint64, len(all))
+	for i, s := range all {
+		offsets[i] = uint64(ehdrSize + body.Len())
+		body.Write(s.data)
+	}
+	shoff := uint64(ehdrSize + body.Len())
+
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	ident := [16]byte{0x7f, 'E', 'L', 'F', byte(elf.ELFCLASS64), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)}
+	buf.Write(ident[:])
+	binary.Write(&buf, le, uint16(elf.ET_REL))
+	binary.Write(&buf, le, uint16(elf.EM_X86_64))
+	binary.Write(&buf, le, uint32(elf.EV_CURRENT))
+	binary.Write(&buf, le, uint64(0))          // entry
+	binary.Write(&buf, le, uint64(0))          // phoff
+	binary.Write(&buf, le, shoff)              // shoff
+	binary.Write(&buf, le, uint32(0))          // flags
+	binary.Write(&buf, le, uint16(ehdrSize))   // ehsize
+	binary.Write(&buf, le, uint16(0))          // phentsize
+	binary.Write(&buf, le, uint16(0))          // phnum
+	binary.Write(&buf, le, uint16(shdrSize))   // shentsize
+	binary.Write(&buf, le, uint16(len(all)+1)) // shnum
+	binary.Write(&buf, le, uint16(len(all)))   // shstrndx
+	buf.Write(body.Bytes())
+
+	buf.Write(make([]byte, shdrSize)) // SHT_NULL
+	for i, s := range all {
+		binary.Write(&buf, le, elf.Section64{
+			Name:      nameOffsets[i],
+			Type:      uint32(s.typ),
+			Off:       offsets[i],
+			Size:      uint64(len(s.data)),
+			Addralign: 1,
+		})
+	}
+	return buf.Bytes()
+}
+
+// buildIDNote encodes a GNU build-id note.
+func buildIDNote(id []byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []uint32{4, uint32(len(id)), 3})
+	buf.WriteString("GNU\x00")
+	buf.Write(id)
+	for buf.Len()%4 != 0 {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+func TestElfDiff(t *testing.T) {
+	text := testSection{".text", elf.SHT_PROGBITS, []byte{0x55, 0x48, 0x89, 0xe5, 0xc3}}
+	a := makeELF([]testSection{
+		text,
+		{".note.gnu.build-id", elf.SHT_NOTE, buildIDNote([]byte{0xaa, 0xbb, 0xcc, 0xdd})},
+		{".debug_str", elf.SHT_PROGBITS, []byte("main.cc\x00/home/alice/src/proj\x00int\x00")},
+		{".data", elf.SHT_PROGBITS, []byte{1, 2, 3, 4}},
+	})
+	b := makeELF([]testSection{
+		text,
+		{".note.gnu.build-id", elf.SHT_NOTE, buildIDNote([]byte{0x11, 0x22, 0x33, 0x44})},
+		{".debug_str", elf.SHT_PROGBITS, []byte("main.cc\x00/home/bob/src/proj\x00int\x00")},
+		{".data", elf.SHT_PROGBITS, []byte{1, 2, 9, 4}},
+		{".comment", elf.SHT_PROGBITS, []byte("GCC: 13.2\x00")},
+	})
+
+	joined := strings.Join(elfDiff(a, b), "\n")
+	for _, want := range []string{
+		"ELF ET_REL EM_X86_64: 5 -> 6 sections",
+		"section .note.gnu.build-id differs (20 -> 20 bytes)\n  - GNU build-id: aabbccdd\n  + GNU build-id: 11223344",
+		"section .debug_str differs (",
+		`  - "/home/alice/src/proj"` + "\n" + `  + "/home/bob/src/proj"`,
+		"section .data differs (4 -> 4 bytes)\n  first difference at section offset 0x2",
+		"added section: .comment (10 bytes)",
+		"section(s) identical",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing %q in:\n%s", want, joined)
+		}
+	}
+	if strings.Contains(joined, "section .text") {
+		t.Errorf(".text is identical and should not be reported:\n%s", joined)
+	}
+}
+
+func TestElfNotes_CorruptHeader(t *testing.T) {
+	var corrupt bytes.Buffer
+	// A name size that wraps to 0 when aligned in 32 bits.
+	binary.Write(&corrupt, binary.LittleEndian, []uint32{0xfffffffe, 4, 3})
+	corrupt.WriteString("GNU\x00")
+	data := append(buildIDNote([]byte{0xaa, 0xbb}), corrupt.Bytes()...)
+
+	got := elfNotes(data, binary.LittleEndian)
+	if len(got) != 1 || got[0] != "GNU build-id: aabb" {
+		t.Errorf("got %q, want only the valid note", got)
+	}
+}
+
+func TestElfDiff_InArArchive(t *testing.T) {
+	objA := makeELF([]testSection{{".rodata", elf.SHT_PROGBITS, []byte("built Mon Jan  1\x00")}})
+	objB := makeELF([]testSection{{".rodata", elf.SHT_PROGBITS, []byte("built Tue Jan  2\x00")}})
+	a := makeAr([]testMember{{name: "stamp.o", content: string(objA), mtime: epoch}})
+	b := makeAr([]testMember{{name: "stamp.o", content: string(objB), mtime: epoch}})
+
+	joined := strings.Join(archiveDiff("ar", a, b), "\n")
+	if !strings.Contains(joined, `section .rodata differs`) || !strings.Contains(joined, `+ "built Tue Jan  2"`) {
+		t.Errorf("expected the object member to be compared as ELF:\n%s", joined)
+	}
+}
+
+func TestPrintableStrings(t *testing.T) {
+	got := printableStrings([]byte("ab\x00abcd\x01\x02hello world\x00xyz"))
+	want := []string{"abcd", "hello world"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	pb "tools/execlog/proto"
+)
+
+// maxFuzzyDistance is the largest edit distance between the keys of two
+// unique actions that are still probably the same action.
+const maxFuzzyDistance = 3
+
+var (
+	// hexRun matches runs of hex digits that may be hashes, e.g. in
+	// "gen-1a2b3c4d.txt".
+	hexRun = regexp.MustCompile(`[0-9a-fA-F]{6,}`)
+	// digitRun matches runs of decimal digits, e.g. in "tmp12345".
+	digitRun = regexp.MustCompile(`[0-9]+`)
+)
+
+// maskKey replaces hashes and numbers in key so that keys differing only in
+// them compare equal. Hex runs without a digit, such as "facade", are words.
+func maskKey(key string) string {
+	key = hexRun.ReplaceAllStringFunc(key, func(s string) string {
+		if !strings.ContainsAny(s, "0123456789") {
+			return s
+		}
+		return "<hash>"
+	})
+	return digitRun.ReplaceAllString(key, "<n>")
+}
+
+// editDistance returns the Levenshtein distance between a and b, in bytes.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// fuzzyPair is a pair of actions unique to two logs that are probably the
+// same action under keys that differ, e.g. by a hash in an output path.
+type fuzzyPair struct {
+	logA, logB int
+	keyA, keyB string
+	a, b       *pb.SpawnExec
+	sections   []string
+}
+
+// uniqueAction is an action found in only one log. Candidates for fuzzy
+// pairing are found by its target label and mnemonic; the action itself is
+// held in exec or, with --streaming, spilled to disk at offset.
+type uniqueAction struct {
+	targetLabel, mnemonic string
+	exec                  *pb.SpawnExec
+	offset                int64
+	size                  int
+}
+
+// newUniqueAction returns exec as a unique action, spilling it if spill is
+// not nil.
+func newUniqueAction(exec *pb.SpawnExec, spill *actionSpill) (uniqueAction, error) {
+	u := uniqueAction{targetLabel: exec.TargetLabel, mnemonic: exec.Mnemonic}
+	if spill == nil {
+		u.exec = exec
+		return u, nil
+	}
+	var err error
+	u.offset, u.size, err = spill.add(exec)
+	return u, err
+}
+
+// load returns the action, reading it back from spill if it was spilled.
+func (u uniqueAction) load(spill *actionSpill) (*pb.SpawnExec, error) {
+	if u.exec != nil {
+		return u.exec, nil
+	}
+	return spill.read(u.offset, u.size)
+}
+
+// fuzzyScore rates how likely the unique actions a and b are the same
+// action: 0 if their keys are equal after masking, else the edit distance
+// between the keys. It returns false if they are probably different.
+// Actions without a target label are never paired, as too many of them
+// share a mnemonic.
+func fuzzyScore(keyA string, a uniqueAction, keyB string, b uniqueAction) (int, bool) {
+	if a.targetLabel == "" || a.mnemonic != b.mnemonic || a.targetLabel != b.targetLabel {
+		return 0, false
+	}
+	if maskKey(keyA) == maskKey(keyB) {
+		return 0, true
+	}
+	if d := editDistance(keyA, keyB); d <= maxFuzzyDistance {
+		return d, true
+	}
+	return 0, false
+}
+
+// fuzzyPairs pairs the actions unique to one log with actions unique to a
+// later log that have the same target label and mnemonic and similar keys,
+// best matches first. It returns the pairs and the actions still unique.
+// Only paired actions are read back from spill.
+func fuzzyPairs(logs []map[string]uniqueAction, uniqueTo [][]string, spill *actionSpill) ([]fuzzyPair, [][]string, error) {
+	paired := make([]map[string]bool, len(logs))
+	for i := range logs {
+		paired[i] = make(map[string]bool)
+	}
+	type candidate struct {
+		score      int
+		keyA, keyB string
+	}
+	var pairs []fuzzyPair
+	for i := range logs {
+		for j := i + 1; j < len(logs); j++ {
+			// Only actions of the same target and mnemonic can match.
+			byTarget := make(map[[2]string][]string)
+			for _, key := range uniqueTo[j] {
+				if !paired[j][key] {
+					u := logs[j][key]
+					target := [2]string{u.targetLabel, u.mnemonic}
+					byTarget[target] = append(byTarget[target], key)
+				}
+			}
+			var candidates []candidate
+			for _, keyA := range uniqueTo[i] {
+				if paired[i][keyA] {
+					continue
+				}
+				a := logs[i][keyA]
+				for _, keyB := range byTarget[[2]string{a.targetLabel, a.mnemonic}] {
+					if score, ok := fuzzyScore(keyA, a, keyB, logs[j][keyB]); ok {
+						candidates = append(candidates, candidate{score, keyA, keyB})
+					}
+				}
+			}
+			sort.Slice(candidates, func(x, y int) bool {
+				cx, cy := candidates[x], candidates[y]
+				if cx.score != cy.score {
+					return cx.score < cy.score
+				}
+				if cx.keyA != cy.keyA {
+					return cx.keyA < cy.keyA
+				}
+				return cx.keyB < cy.keyB
+			})
+			for _, c := range candidates {
+				if paired[i][c.keyA] || paired[j][c.keyB] {
+					continue
+				}
+				paired[i][c.keyA], paired[j][c.keyB] = true, true
+				a, err := logs[i][c.keyA].load(spill)
+				if err != nil {
+					return nil, nil, err
+				}
+				b, err := logs[j][c.keyB].load(spill)
+				if err != nil {
+					return nil, nil, err
+				}
+				pairs = append(pairs, fuzzyPair{
+					logA: i, logB: j,
+					keyA: c.keyA, keyB: c.keyB,
+					a: a, b: b,
+					sections: diffSections(a, b),
+				})
+			}
+		}
+	}
+
+	remaining := make([][]string, len(uniqueTo))
+	for i, keys := range uniqueTo {
+		for _, key := range keys {
+			if !paired[i][key] {
+				remaining[i] = append(remaining[i], key)
+			}
+		}
+	}
+	return pairs, remaining, nil
+}
+
+// formatFuzzyPair returns the lines of the text report for a probable pair.
+func formatFuzzyPair(p fuzzyPair, verbose bool) []string {
+	header := fmt.Sprintf("  %s (log%d) ~ %s (log%d) [%s]", p.keyA, p.logA+1, p.keyB, p.logB+1, p.a.Mnemonic)
+	if p.a.TargetLabel != "" {
+		header += fmt.Sprintf(" (%s)", p.a.TargetLabel)
+	}
+	lines := []string{header}
+	if len(p.sections) == 0 {
+		return append(lines, "    identical apart from the key")
+	}
+	lines = append(lines, fmt.Sprintf("    differs in: %s", strings.Join(p.sections, ", ")))
+	if !verbose {
+		return lines
+	}
+	for _, section := range p.sections {
+		details := verboseDetails(section, p.a, p.b)
+		if len(details) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("    %s:", section))
+		for _, line := range details {
+			lines = append(lines, "      "+line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestMaskKey(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"out/gen-1a2b3c4d.txt", "out/gen-9f8e7d6c.txt"},
+		{"out/tmp12345/x.o", "out/tmp678/x.o"},
+		{"out/facade.txt", "out/facade.txt"},
+	}
+	for _, tt := range tests {
+		if maskKey(tt.a) != maskKey(tt.b) {
+			t.Errorf("maskKey(%q) = %q, maskKey(%q) = %q; want equal", tt.a, maskKey(tt.a), tt.b, maskKey(tt.b))
+		}
+	}
+	if got := maskKey("out/facade.txt"); got != "out/facade.txt" {
+		t.Errorf("expected a hex word to stay, got %q", got)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"out/a.txt", "out/a.txt", 0},
+		{"out/a_x.txt", "out/a-y.txt", 2},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyPairs(t *testing.T) {
+	dir := t.TempDir()
+	action := func(output, target, hash string) *pb.SpawnExec {
+		exec := genrule(output, hash)
+		exec.TargetLabel = target
+		return exec
+	}
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{
+		action("out/gen-1a2b3c4d.txt", "//pkg:gen", "aaa"),
+		action("out/lib_v1.a", "//pkg:lib", "bbb"),
+		action("out/other.txt", "//pkg:other", "ccc"),
+	})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{
+		action("out/gen-9f8e7d6c.txt", "//pkg:gen", "aaa"),
+		action("out/lib_v2.a", "//pkg:lib", "ddd"),
+		action("out/different.txt", "//pkg:other", "ccc"),
+	})
+
+	out := captureStdout(t, func() {
+		run([]string{log1, log2}, "", true)
+	})
+	for _, want := range []string{
+		"Actions unique to log1: 1\n  out/other.txt\n",
+		"Actions unique to log2: 1\n  out/different.txt\n",
+		"Probably paired actions: 2\n",
+		"  out/gen-1a2b3c4d.txt (log1) ~ out/gen-9f8e7d6c.txt (log2) [Genrule] (//pkg:gen)\n    differs in: command_args, listed_outputs, actual_outputs\n",
+		"  out/lib_v1.a (log1) ~ out/lib_v2.a (log2) [Genrule] (//pkg:lib)\n",
+		"    actual_outputs:\n        removed: out/lib_v1.a (hash=bbb size=10)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	out = captureStdout(t, func() {
+		runWithOptions([]string{log1, log2}, options{outputFormat: "json"})
+	})
+	var rep jsonReport
+	if err := json.Unmarshal([]byte(out), &rep); err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.ProbablyPaired) != 2 || rep.ProbablyPaired[0].Keys[1] != "out/gen-9f8e7d6c.txt" || len(rep.ProbablyPaired[0].Sections) != 3 {
+		t.Errorf("unexpected probably_paired: %+v", rep.ProbablyPaired)
+	}
+	if rep.Summary.UniqueActions != 2 {
+		t.Errorf("got %d unique actions, want 2", rep.Summary.UniqueActions)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// htmlFuncs are the helpers of htmlTemplate.
+var htmlFuncs = template.FuncMap{
+	// changeLabel names what a change is about: a command argument
+	// position, a variable, property or path.
+	"changeLabel": func(c change) string {
+		if c.Index != nil {
+			return strings.TrimSpace(fmt.Sprintf("[%d] %s", *c.Index, c.Flag))
+		}
+		return c.Name
+	},
+	// changeValue returns the old or new value of a change, or "" if the
+	// change has none.
+	"changeValue": func(c change, new bool) string {
+		value, digest := c.Old, c.OldDigest
+		if new {
+			value, digest = c.New, c.NewDigest
+		}
+		switch {
+		case value != nil:
+			return fmt.Sprintf("%q", *value)
+		case digest != nil:
+			return formatDigest(digest)
+		}
+		return ""
+	},
+	"join": func(strs []string) string {
+		return strings.Join(strs, ", ")
+	},
+}
+
+// htmlTemplate is the --html_report page. It is a single file with inline
+// styles and scripts, so it can be attached to a CI run and opened as is.
+var htmlTemplate = template.Must(template.New("report").Funcs(htmlFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Determinism report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+#actions { width: 100%; }
+#actions > thead th { cursor: pointer; text-align: left; background: #eee; padding: 4px 8px; position: sticky; top: 0; }
+#actions > thead th.asc::after { content: " \25B2"; }
+#actions > thead th.desc::after { content: " \25BC"; }
+#actions > tbody > tr.action > td { padding: 4px 8px; border-top: 1px solid #ddd; cursor: pointer; }
+#actions > tbody > tr.action:hover { background: #f5f8ff; }
+tr.group > td { font-weight: bold; padding: 12px 8px 4px; border-bottom: 2px solid #888; }
+tr.details > td { padding: 4px 24px 12px; background: #fafafa; }
+.side { width: 100%; table-layout: fixed; font-family: monospace; font-size: 90%; }
+.side td, .side th { border: 1px solid #ddd; padding: 2px 6px; vertical-align: top; word-break: break-all; }
+.side td:first-child { width: 20%; }
+.removed .old, .changed .old { background: #fdd; }
+.added .new, .changed .new { background: #dfd; }
+.origin { color: #b00; }
+.propagated { color: #a60; }
+.ordering_only { color: #06a; }
+pre { background: #f0f0f0; padding: 8px; overflow-x: auto; }
+.controls { margin: 1em 0; }
+[hidden] { display: none; }
+</style>
+</head>
+<body>
+<h1>Determinism report</h1>
+<p>{{.Summary.PairedActions}} paired actions compared across {{len .Logs}} logs:
+<b>{{.Summary.NonDeterministic}} non-deterministic</b>
+({{.Summary.Origins}} origin, {{.Summary.Propagated}} propagated, {{.Summary.OrderingOnly}} ordering-only),
+{{.Summary.Suppressed}} suppressed, {{.Summary.Skipped}} skipped as neither remotable nor cacheable,
+{{.Summary.UniqueActions}} found in one log only.</p>
+<ol>{{range .Logs}}<li><code>{{.}}</code></li>{{end}}</ol>
+{{if .Actions}}
+<div class="controls">
+<input id="search" type="search" placeholder="Search actions" size="40">
+<label>Group by <select id="group"><option value="">nothing</option><option value="target">target</option><option value="mnemonic">mnemonic</option></select></label>
+<span id="count"></span>
+</div>
+<table id="actions">
+<thead><tr><th>Output</th><th>Mnemonic</th><th>Target</th><th>Category</th><th>Differs in</th><th>Likely cause</th></tr></thead>
+<tbody>
+{{range .Actions}}
+<tr class="action" data-target="{{.TargetLabel}}" data-mnemonic="{{.Mnemonic}}">
+<td><code>{{.Key}}</code></td><td>{{.Mnemonic}}</td><td>{{.TargetLabel}}</td>
+<td class="{{.Category}}">{{.Category}}</td><td>{{join .Sections}}</td><td>{{join .LikelyCauses}}</td>
+</tr>
+<tr class="details" hidden><td colspan="6">
+{{if .Upstream}}<p>Propagated from: {{range .Upstream}}<code>{{.}}</code> {{end}}</p>{{end}}
+{{if and (gt (len .Majority) 1) (not .NoMajority)}}<p>Majority: {{range .Majority}}log{{.}} {{end}}&mdash; outliers: {{range .Outliers}}log{{.}} {{end}}</p>{{end}}
+{{if .NoMajority}}<p>No majority: reference {{range .Majority}}log{{.}} {{end}}&mdash; others: {{range .Outliers}}log{{.}} {{end}}</p>{{end}}
+{{range .Comparisons}}{{$ref := .Reference}}{{$log := .Log}}
+<h4>log{{$log}} vs log{{$ref}}</h4>
+{{range .Sections}}
+<details><summary>{{.Name}} ({{len .Changes}} change(s))</summary>
+<table class="side">
+<tr><th></th><th>log{{$ref}}</th><th>log{{$log}}</th></tr>
+{{range .Changes}}<tr class="{{.Kind}}"><td>{{changeLabel .}}</td><td class="old">{{changeValue . false}}</td><td class="new">{{changeValue . true}}</td></tr>
+{{end}}</table>
+{{if .ContentDiff}}<pre>{{range .ContentDiff}}{{.}}
+{{end}}</pre>{{end}}
+</details>
+{{end}}{{end}}
+</td></tr>
+{{end}}
+</tbody>
+</table>
+{{else}}
+<p>No non-deterministic actions found.</p>
+{{end}}
+{{if .Suppressed}}
+<h2>Suppressed actions</h2>
+<table class="side">
+<tr><th>Output</th><th>Reason</th><th>Owner</th></tr>
+{{range .Suppressed}}<tr><td>{{.Key}}</td><td>{{.Suppression.Reason}}</td><td>{{.Suppression.Owner}}</td></tr>
+{{end}}</table>
+{{end}}
+<script>
+(function() {
+  var table = document.getElementById("actions");
+  if (!table) return;
+  var body = table.tBodies[0];
+  var rows = [];
+  var trs = body.querySelectorAll("tr.action");
+  for (var i = 0; i < trs.length; i++) {
+    var tr = trs[i];
+    rows.push({action: tr, details: tr.nextElementSibling, text: (tr.textContent + " " + tr.nextElementSibling.textContent).toLowerCase()});
+    tr.addEventListener("click", function() {
+      var d = this.nextElementSibling;
+      d.hidden = !d.hidden;
+    });
+  }
+  var sortCol = -1, sortDir = 1;
+  var search = document.getElementById("search");
+  var group = document.getElementById("group");
+  var count = document.getElementById("count");
+
+  function render() {
+    var query = search.value.toLowerCase();
+    var by = group.value;
+    var sorted = rows.slice();
+    sorted.sort(function(a, b) {
+      if (by) {
+        var ga = a.action.dataset[by], gb = b.action.dataset[by];
+        if (ga !== gb) return ga < gb ? -1 : 1;
+      }
+      if (sortCol < 0) return 0;
+      var x = a.action.cells[sortCol].textContent, y = b.action.cells[sortCol].textContent;
+      return x === y ? 0 : (x < y ? -sortDir : sortDir);
+    });
+    var old = body.querySelectorAll("tr.group");
+    for (var i = 0; i < old.length; i++) old[i].remove();
+    var shown = 0, last = null;
+    sorted.forEach(function(r) {
+      var match = r.text.indexOf(query) >= 0;
+      r.action.hidden = !match;
+      if (!match) r.details.hidden = true;
+      if (match && by && r.action.dataset[by] !== last) {
+        last = r.action.dataset[by];
+        var g = document.createElement("tr");
+        g.className = "group";
+        g.innerHTML = "<td colspan=6></td>";
+        g.firstChild.textContent = last || "(none)";
+        body.appendChild(g);
+      }
+      body.appendChild(r.action);
+      body.appendChild(r.details);
+      if (match) shown++;
+    });
+    count.textContent = shown + " of " + rows.length + " action(s)";
+  }
+
+  var ths = table.tHead.rows[0].cells;
+  for (var i = 0; i < ths.length; i++) {
+    (function(col) {
+      ths[col].addEventListener("click", function() {
+        sortDir = sortCol === col ? -sortDir : 1;
+        sortCol = col;
+        for (var j = 0; j < ths.length; j++) ths[j].className = "";
+        ths[col].className = sortDir > 0 ? "asc" : "desc";
+        render();
+      });
+    })(i);
+  }
+  search.addEventListener("input", render);
+  group.addEventListener("change", render);
+  render();
+})();
+</script>
+</body>
+</html>
+`))
+
+// writeHTMLReport writes r to w as a self-contained HTML page.
+func writeHTMLReport(w io.Writer, r *checkResult) error {
+	return htmlTemplate.Execute(w, newJSONReport(r))
+}
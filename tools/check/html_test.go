@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestHTMLReport(t *testing.T) {
+	dir := t.TempDir()
+	a := genrule("out/a.txt", "aaa")
+	a.TargetLabel = "//pkg:a"
+	b := genrule("out/a.txt", "bbb")
+	b.TargetLabel = "//pkg:a"
+	b.CommandArgs = []string{"/bin/echo", "<script>alert(1)</script>"}
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{a, genrule("out/same.txt", "ccc")})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{b, genrule("out/same.txt", "ccc")})
+	path := filepath.Join(dir, "report.html")
+
+	out := captureStdout(t, func() {
+		runWithOptions([]string{log1, log2}, options{htmlReport: path})
+	})
+	if !strings.Contains(out, "Non-deterministic actions found: 1") {
+		t.Errorf("expected the text report on stdout, got:\n%s", out)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(data)
+	for _, want := range []string{
+		"2 paired actions compared across 2 logs",
+		`<tr class="action" data-target="//pkg:a" data-mnemonic="Genrule">`,
+		"<td><code>out/a.txt</code></td>",
+		`<td class="origin">origin</td>`,
+		"<summary>command_args (1 change(s))</summary>",
+		`<td>[1]</td><td class="old">&#34;out/a.txt&#34;</td><td class="new">&#34;&lt;script&gt;alert(1)&lt;/script&gt;&#34;</td>`,
+		"<summary>actual_outputs (1 change(s))</summary>",
+		`<td class="old">hash=aaa size=10</td><td class="new">hash=bbb size=10</td>`,
+		`<input id="search"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+	if strings.Contains(page, "<script>alert") {
+		t.Error("command arguments are not escaped")
+	}
+	for _, external := range []string{"src=", "href=", "http://", "https://"} {
+		if strings.Contains(page, external) {
+			t.Errorf("report refers to an external asset: %q", external)
+		}
+	}
+}
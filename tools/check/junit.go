@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// JUnit report groupings.
+const (
+	junitByAction = "action"
+	junitByTarget = "target"
+)
+
+// junitSuites is the root element of a JUnit XML report, in the format read
+// by Jenkins and GitLab.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitEntry collects the outcome of the actions in one test case.
+type junitEntry struct {
+	name, classname string
+	failures        []diffResult
+	skipped         []string
+	passed          int
+}
+
+// junitCases returns one test case per paired action, or per target label
+// with groupBy junitByTarget. A case fails if any of its actions is
+// non-deterministic, and is skipped if all of its actions were skipped or
+// suppressed.
+func junitCases(r *checkResult, groupBy string) []junitCase {
+	entries := make(map[string]*junitEntry)
+	entry := func(key, mnemonic, targetLabel string) *junitEntry {
+		name, classname := key, mnemonic
+		if groupBy == junitByTarget {
+			name, classname = targetLabel, "target"
+			if targetLabel == "" {
+				name, classname = key, mnemonic
+			}
+		}
+		e, ok := entries[name]
+		if !ok {
+			e = &junitEntry{name: name, classname: classname}
+			entries[name] = e
+		}
+		return e
+	}
+	for _, a := range r.passed {
+		entry(a.key, a.mnemonic, a.targetLabel).passed++
+	}
+	for _, a := range r.skipped {
+		e := entry(a.key, a.mnemonic, a.targetLabel)
+		e.skipped = append(e.skipped, a.key+": differs, but is neither remotable nor cacheable")
+	}
+	for _, d := range r.suppressed {
+		e := entry(d.key, d.mnemonic, d.targetLabel)
+		e.skipped = append(e.skipped, fmt.Sprintf("%s: suppressed: %s (owner: %s)", d.key, d.suppressedBy.Reason, d.suppressedBy.Owner))
+	}
+	for _, d := range r.reported {
+		e := entry(d.key, d.mnemonic, d.targetLabel)
+		e.failures = append(e.failures, d)
+	}
+
+	nWay := len(r.logs) > 2
+	var cases []junitCase
+	for _, e := range entries {
+		c := junitCase{Name: e.name, Classname: e.classname}
+		switch {
+		case len(e.failures) > 0:
+			var messages, details []string
+			for _, d := range e.failures {
+				messages = append(messages, fmt.Sprintf("%s differs in %s", d.key, strings.Join(d.sections, ", ")))
+				details = append(details, formatDiffResult(d, true, r.store, nWay)...)
+			}
+			c.Failure = &junitFailure{
+				Message: "non-deterministic: " + strings.Join(messages, "; "),
+				Type:    category(e.failures[0]),
+				Details: strings.Join(details, "\n"),
+			}
+		case e.passed == 0 && len(e.skipped) > 0:
+			c.Skipped = &junitSkipped{Message: strings.Join(e.skipped, "; ")}
+		}
+		cases = append(cases, c)
+	}
+	sort.Slice(cases, func(i, j int) bool {
+		if cases[i].Classname != cases[j].Classname {
+			return cases[i].Classname < cases[j].Classname
+		}
+		return cases[i].Name < cases[j].Name
+	})
+	return cases
+}
+
+// writeJUnitReport writes r to w as JUnit XML, with test cases grouped by
+// groupBy.
+func writeJUnitReport(w io.Writer, r *checkResult, groupBy string) error {
+	suite := junitSuite{Name: "determinism", Cases: junitCases(r, groupBy)}
+	for _, c := range suite.Cases {
+		suite.Tests++
+		if c.Failure != nil {
+			suite.Failures++
+		}
+		if c.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+	report := junitSuites{
+		Name:     "check",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []junitSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
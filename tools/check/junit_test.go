@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestJUnitReport(t *testing.T) {
+	dir := t.TempDir()
+	withLabel := func(e *pb.SpawnExec, label string) *pb.SpawnExec {
+		e.TargetLabel = label
+		return e
+	}
+	local := func(e *pb.SpawnExec) *pb.SpawnExec {
+		e.Remotable, e.Cacheable = false, false
+		return e
+	}
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{
+		withLabel(genrule("out/same.txt", "aaa"), "//pkg:a"),
+		withLabel(genrule("out/differs.txt", "bbb"), "//pkg:a"),
+		local(genrule("out/local.txt", "ccc")),
+		genrule("out/known.txt", "ddd"),
+	})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{
+		withLabel(genrule("out/same.txt", "aaa"), "//pkg:a"),
+		withLabel(genrule("out/differs.txt", "BBB"), "//pkg:a"),
+		local(genrule("out/local.txt", "CCC")),
+		genrule("out/known.txt", "DDD"),
+	})
+	cfg := writeConfig(t, dir, `{"suppressions": [{"output": "out/known.txt", "owner": "team", "reason": "embeds a date"}]}`)
+	path := filepath.Join(dir, "junit.xml")
+
+	read := func() junitSuites {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var suites junitSuites
+		if err := xml.Unmarshal(data, &suites); err != nil {
+			t.Fatalf("invalid JUnit XML: %v\n%s", err, data)
+		}
+		return suites
+	}
+
+	captureStdout(t, func() {
+		runWithOptions([]string{log1, log2}, options{configPath: cfg, junitReport: path})
+	})
+	suites := read()
+	if suites.Tests != 4 || suites.Failures != 1 || suites.Skipped != 2 {
+		t.Errorf("got %d tests, %d failures, %d skipped; want 4, 1, 2", suites.Tests, suites.Failures, suites.Skipped)
+	}
+	cases := make(map[string]junitCase)
+	for _, c := range suites.Suites[0].Cases {
+		cases[c.Name] = c
+	}
+	if c := cases["out/same.txt"]; c.Failure != nil || c.Skipped != nil || c.Classname != "Genrule" {
+		t.Errorf("expected out/same.txt to pass, got %+v", c)
+	}
+	failure := cases["out/differs.txt"].Failure
+	if failure == nil || failure.Type != "origin" || !strings.Contains(failure.Message, "out/differs.txt differs in actual_outputs") {
+		t.Fatalf("expected out/differs.txt to fail, got %+v", cases["out/differs.txt"])
+	}
+	if !strings.Contains(failure.Details, "changed: out/differs.txt (hash=bbb size=10 -> hash=BBB size=10)") {
+		t.Errorf("expected verbose details in the failure, got:\n%s", failure.Details)
+	}
+	if c := cases["out/local.txt"]; c.Skipped == nil || !strings.Contains(c.Skipped.Message, "neither remotable nor cacheable") {
+		t.Errorf("expected out/local.txt to be skipped, got %+v", c)
+	}
+	if c := cases["out/known.txt"]; c.Skipped == nil || !strings.Contains(c.Skipped.Message, "suppressed: embeds a date (owner: team)") {
+		t.Errorf("expected out/known.txt to be skipped as suppressed, got %+v", c)
+	}
+
+	captureStdout(t, func() {
+		runWithOptions([]string{log1, log2}, options{configPath: cfg, junitReport: path, junitGroupBy: junitByTarget})
+	})
+	suites = read()
+	if suites.Tests != 3 || suites.Failures != 1 {
+		t.Errorf("by target: got %d tests, %d failures; want 3, 1", suites.Tests, suites.Failures)
+	}
+	for _, c := range suites.Suites[0].Cases {
+		if c.Name == "//pkg:a" && c.Failure == nil {
+			t.Errorf("expected //pkg:a to fail, got %+v", c)
+		}
+	}
+
+	if code := runWithOptions([]string{log1, log2}, options{junitGroupBy: "package"}); code != exitUsageError {
+		t.Errorf("unknown grouping: got exit code %d, want %d", code, exitUsageError)
+	}
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	pb "tools/execlog/proto"
+)
+
+// Likely causes of an output difference, guessed from the content of both
+// versions of the output.
+const (
+	likelyTimestamp  = "timestamp"
+	likelyBuildPath  = "build_path"
+	likelyUserOrHost = "user_or_host"
+	likelyRandom     = "random"
+	likelyOrdering   = "ordering"
+	// likelyUnknown is used when the content was compared but no heuristic
+	// matched.
+	likelyUnknown = "unknown"
+)
+
+// likelyCauseOrder lists the likely causes in the order they are reported.
+var likelyCauseOrder = []string{
+	likelyTimestamp,
+	likelyBuildPath,
+	likelyUserOrHost,
+	likelyRandom,
+	likelyOrdering,
+	likelyUnknown,
+}
+
+var (
+	// timestampToken matches dates, times of day, weekday and month names as
+	// printed by date(1), and Unix times in seconds or milliseconds.
+	timestampToken = regexp.MustCompile(`\d{4}-\d{2}-\d{2}|\d{4}/\d{2}/\d{2}|\d{1,2}:\d{2}:\d{2}|` +
+		`^(Mon|Tue|Wed|Thu|Fri|Sat|Sun|Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)[a-z]*,?$|^1\d{9}(\d{3})?$`)
+	// buildPathToken matches absolute Unix or Windows paths and Bazel's
+	// execroot and sandbox directories.
+	buildPathToken = regexp.MustCompile(`(^|[=:])/[^/]+/|^[A-Za-z]:\\|execroot|sandbox`)
+	// userOrHostToken matches assignments such as "user=alice" or
+	// "build_host=ci-7".
+	userOrHostToken = regexp.MustCompile(`(?i)^[\w.-]*(user|host|login|whoami)[\w.-]*[=:]`)
+	// randomToken matches long hex or base64 strings.
+	randomToken = regexp.MustCompile(`^[A-Za-z0-9+/_-]{16,}={0,2}$`)
+)
+
+// identityVariables are the environment variables whose values identify the
+// user or machine running a build.
+var identityVariables = []string{"USER", "LOGNAME", "USERNAME", "HOSTNAME", "HOST", "COMPUTERNAME"}
+
+// identities returns the user and host names found in the environment of
+// the given actions.
+func identities(execs ...*pb.SpawnExec) []string {
+	var ids []string
+	for _, exec := range execs {
+		for _, e := range exec.EnvironmentVariables {
+			if slices.Contains(identityVariables, e.Name) && len(e.Value) >= 3 {
+				ids = append(ids, e.Value)
+			}
+		}
+	}
+	return ids
+}
+
+// likelyCauses guesses the causes of the output differences of d by reading
+// both versions of every differing output from store. It returns nil if no
+// content could be read.
+func likelyCauses(store blobStore, d *diffResult) []string {
+	seen := make(map[string]bool)
+	for i, outlier := range d.outlierExecs {
+		ids := identities(d.a, outlier)
+		aFiles := make(map[string]*pb.File)
+		for _, f := range d.a.ActualOutputs {
+			aFiles[f.Path] = f
+		}
+		for _, f := range outlier.ActualOutputs {
+			fa, ok := aFiles[f.Path]
+			if !ok || fa.Digest == nil || f.Digest == nil || fa.Digest.Hash == f.Digest.Hash {
+				continue
+			}
+			a, err := store.read(d.majority[0], fa)
+			if err != nil {
+				continue
+			}
+			b, err := store.read(d.outliers[i], f)
+			if err != nil {
+				continue
+			}
+			for _, c := range classifyContent(fa.Path, a, b, ids) {
+				seen[c] = true
+			}
+		}
+	}
+	return orderedCauses(seen)
+}
+
+// orderedCauses returns the causes in seen in likelyCauseOrder, dropping
+// likelyUnknown if another cause was found.
+func orderedCauses(seen map[string]bool) []string {
+	if len(seen) > 1 {
+		delete(seen, likelyUnknown)
+	}
+	var causes []string
+	for _, c := range likelyCauseOrder {
+		if seen[c] {
+			causes = append(causes, c)
+		}
+	}
+	return causes
+}
+
+// classifyContent guesses why the output at path changed from a to b. ids
+// are user and host names that may leak into the output.
+func classifyContent(path string, a, b []byte, ids []string) []string {
+	seen := make(map[string]bool)
+	if format := archiveFormat(path, a); format != "" && format == archiveFormat(path, b) {
+		classifyArchive(format, a, b, ids, seen)
+	} else if isText(a) && isText(b) {
+		classifyText(a, b, ids, seen)
+	} else {
+		removed, added := stringsDiff(printableStrings(a), printableStrings(b))
+		classifyStrings(removed, added, ids, seen)
+		// Content shifted by a changed string differs everywhere after it,
+		// so differing bytes only count when nothing else explains them.
+		if len(seen) == 0 && hasRandomBytes(a, b) {
+			seen[likelyRandom] = true
+		}
+	}
+	if len(seen) == 0 {
+		seen[likelyUnknown] = true
+	}
+	return orderedCauses(seen)
+}
+
+// classifyText classifies a text output: lines that only moved are an
+// ordering change, otherwise the changed lines are scanned.
+func classifyText(a, b []byte, ids []string, seen map[string]bool) {
+	linesA, linesB := splitLines(a), splitLines(b)
+	sortedA, sortedB := slices.Clone(linesA), slices.Clone(linesB)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	if slices.Equal(sortedA, sortedB) {
+		seen[likelyOrdering] = true
+		return
+	}
+	removed, added := stringsDiff(linesA, linesB)
+	classifyStrings(removed, added, ids, seen)
+}
+
+// classifyArchive classifies an archive output from the members that moved,
+// the metadata that changed and the content of the members that changed.
+// Members count as moved only when those in both archives appear in a
+// different relative order, not when an added or removed member shifts the
+// ones after it.
+func classifyArchive(format string, a, b []byte, ids []string, seen map[string]bool) {
+	ma, errA := readArchive(format, a)
+	mb, errB := readArchive(format, b)
+	if errA != nil || errB != nil {
+		return
+	}
+	keysA, keysB := memberKeys(ma), memberKeys(mb)
+	indexB := make(map[string]int)
+	for i, k := range keysB {
+		indexB[k] = i
+	}
+	// commonA and commonB are the keys found in both archives, in the
+	// order of each.
+	var commonA, commonB []string
+	inA := make(map[string]bool)
+	for i, k := range keysA {
+		j, ok := indexB[k]
+		if !ok {
+			continue
+		}
+		commonA = append(commonA, k)
+		inA[k] = true
+		x, y := ma[i], mb[j]
+		if x.sum != y.sum {
+			for _, c := range classifyContent(k, x.content, y.content, ids) {
+				if c != likelyUnknown {
+					seen[c] = true
+				}
+			}
+		}
+		for f := range x.metadata {
+			if x.metadata[f] == y.metadata[f] {
+				continue
+			}
+			switch x.metadata[f][0] {
+			case "mtime":
+				seen[likelyTimestamp] = true
+			case "uid", "gid", "uname", "gname":
+				seen[likelyUserOrHost] = true
+			}
+		}
+	}
+	for _, k := range keysB {
+		if inA[k] {
+			commonB = append(commonB, k)
+		}
+	}
+	if !slices.Equal(commonA, commonB) {
+		seen[likelyOrdering] = true
+	}
+}
+
+// classifyStrings scans the changed strings of an output. The strings are
+// split into tokens and only the tokens that changed are matched, so that a
+// constant path on the same line as a timestamp is not reported.
+func classifyStrings(removedStrs, addedStrs []string, ids []string, seen map[string]bool) {
+	removed, added := stringsDiff(tokens(removedStrs), tokens(addedStrs))
+	for _, token := range append(removed, added...) {
+		matched := false
+		if timestampToken.MatchString(token) {
+			seen[likelyTimestamp] = true
+			matched = true
+		}
+		if buildPathToken.MatchString(token) {
+			seen[likelyBuildPath] = true
+			matched = true
+		}
+		if userOrHostToken.MatchString(token) || containsAny(token, ids) {
+			seen[likelyUserOrHost] = true
+			matched = true
+		}
+		if !matched && randomToken.MatchString(token) && strings.ContainsAny(token, "0123456789") {
+			seen[likelyRandom] = true
+		}
+	}
+}
+
+// tokens splits strings at whitespace, quotes and separators.
+func tokens(strs []string) []string {
+	var result []string
+	for _, s := range strs {
+		result = append(result, strings.FieldsFunc(s, func(r rune) bool {
+			return strings.ContainsRune(" \t\r\n\"'`,;()[]{}<>", r)
+		})...)
+	}
+	return result
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRandomBytes reports whether a and b differ in a run of at least 8
+// bytes of which nearly all differ, as random data or a hash would.
+func hasRandomBytes(a, b []byte) bool {
+	const minRun = 8
+	n := min(len(a), len(b))
+	start, differing := -1, 0
+	for i := 0; i <= n; i++ {
+		if i < n && a[i] != b[i] {
+			if start < 0 {
+				start = i
+			}
+			differing++
+			continue
+		}
+		// Allow single equal bytes inside a run, as random bytes match by
+		// chance one time in 256.
+		if i < n && start >= 0 && i+1 < n && a[i+1] != b[i+1] {
+			continue
+		}
+		if start >= 0 && i-start >= minRun && differing*4 >= (i-start)*3 {
+			return true
+		}
+		start, differing = -1, 0
+	}
+	return false
+}
+
+// filterLikelyCauses splits results into those with at least one of causes
+// and the number of others. An empty causes list keeps every result.
+func filterLikelyCauses(results []diffResult, causes []string) ([]diffResult, int) {
+	if len(causes) == 0 {
+		return results, 0
+	}
+	var kept []diffResult
+	for _, d := range results {
+		for _, c := range d.likelyCauses {
+			if slices.Contains(causes, c) {
+				kept = append(kept, d)
+				break
+			}
+		}
+	}
+	return kept, len(results) - len(kept)
+}
+
+// groupByLikelyCause regroups results by their combination of likely causes,
+// in likelyCauseOrder. Results without a classification come last.
+func groupByLikelyCause(results []diffResult) []resultGroup {
+	var groups []resultGroup
+	index := make(map[string]int)
+	for _, d := range results {
+		title := "Likely cause: " + strings.Join(d.likelyCauses, ", ")
+		if len(d.likelyCauses) == 0 {
+			title = "Likely cause: not classified (content not available)"
+		}
+		i, ok := index[title]
+		if !ok {
+			i = len(groups)
+			index[title] = i
+			groups = append(groups, resultGroup{title: title})
+		}
+		groups[i].results = append(groups[i].results, d)
+	}
+	rank := func(causes []string) []int {
+		ranks := make([]int, len(causes))
+		for i, c := range causes {
+			ranks[i] = slices.Index(likelyCauseOrder, c)
+		}
+		if len(ranks) == 0 {
+			ranks = []int{len(likelyCauseOrder)}
+		}
+		return ranks
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return slices.Compare(rank(groups[i].results[0].likelyCauses), rank(groups[j].results[0].likelyCauses)) < 0
+	})
+	return groups
+}
+
+// parseLikelyCauses parses a comma-separated --likely_cause value.
+func parseLikelyCauses(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var causes []string
+	for _, c := range strings.Split(value, ",") {
+		c = strings.TrimSpace(c)
+		if !slices.Contains(likelyCauseOrder, c) {
+			return nil, fmt.Errorf("unknown likely cause %q, want one of %s", c, strings.Join(likelyCauseOrder, ", "))
+		}
+		causes = append(causes, c)
+	}
+	return causes, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	pb "tools/execlog/proto"
+)
+
+func TestClassifyContent(t *testing.T) {
+	random1 := []byte("\x00\x01\x02\x03\x9a\x3c\x71\xe0\x55\x12\xfe\x08\x44\x21\x00\x01")
+	random2 := []byte("\x00\x01\x02\x03\x17\xb2\x0c\x9d\xa1\x6e\x33\xc7\x90\x5b\x00\x01")
+
+	tests := []struct {
+		name string
+		path string
+		a, b []byte
+		ids  []string
+		want []string
+	}{
+		{"date", "out.txt", []byte("hello\nThu Oct 16 12:00:01 UTC 2026\n"), []byte("hello\nThu Oct 16 12:04:59 UTC 2026\n"), nil, []string{likelyTimestamp}},
+		{"iso date", "out.txt", []byte("built 2026-10-15\n"), []byte("built 2026-10-16\n"), nil, []string{likelyTimestamp}},
+		{"epoch", "out.txt", []byte("stamp 1760000000\n"), []byte("stamp 1760000042\n"), nil, []string{likelyTimestamp}},
+		{"user", "out.txt", []byte("user=alice\n"), []byte("user=bob\n"), nil, []string{likelyUserOrHost}},
+		{"hostname", "out.txt", []byte("hostname=ci-1\n"), []byte("hostname=ci-2\n"), nil, []string{likelyUserOrHost}},
+		{"known identity", "out.txt", []byte("by alice\n"), []byte("by robert\n"), []string{"alice", "robert"}, []string{likelyUserOrHost}},
+		{"build path", "out.txt", []byte("src /home/alice/ws/a.c 2026\n"), []byte("src /tmp/ws/a.c 2026\n"), nil, []string{likelyBuildPath}},
+		{"random", "out.txt", []byte("a\nQk9PTVNUSUNLMTIzNDU2\n"), []byte("a\nZm9vYmFyYmF6OTg3NjU0\n"), nil, []string{likelyRandom}},
+		{"ordering", "out.txt", []byte("a\nb\nc\n"), []byte("c\na\nb\n"), nil, []string{likelyOrdering}},
+		{"unknown", "out.txt", []byte("version 1\n"), []byte("version 2\n"), nil, []string{likelyUnknown}},
+		{"random bytes", "out.bin", random1, random2, nil, []string{likelyRandom}},
+		{"binary path", "out.o", []byte("\x00\x01/home/alice/src\x00\x02"), []byte("\x00\x01/home/bob/src\x00\x02"), nil, []string{likelyBuildPath}},
+		{"tar mtime", "out.tar",
+			makeTar(t, []testMember{{name: "a", content: "x", mtime: epoch}}),
+			makeTar(t, []testMember{{name: "a", content: "x", mtime: epoch.Add(time.Hour)}}),
+			nil, []string{likelyTimestamp}},
+		{"tar uid", "out.tar",
+			makeTar(t, []testMember{{name: "a", content: "x", mtime: epoch, uid: 1000}}),
+			makeTar(t, []testMember{{name: "a", content: "x", mtime: epoch, uid: 1001}}),
+			nil, []string{likelyUserOrHost}},
+		{"zip order", "out.zip",
+			makeZip(t, []testMember{{name: "a", content: "x", mtime: epoch}, {name: "b", content: "y", mtime: epoch}}),
+			makeZip(t, []testMember{{name: "b", content: "y", mtime: epoch}, {name: "a", content: "x", mtime: epoch}}),
+			nil, []string{likelyOrdering}},
+		{"zip member added and removed", "out.zip",
+			makeZip(t, []testMember{{name: "a", content: "x", mtime: epoch}, {name: "b", content: "y", mtime: epoch}}),
+			makeZip(t, []testMember{{name: "b", content: "y", mtime: epoch}, {name: "c", content: "z", mtime: epoch}}),
+			nil, []string{likelyUnknown}},
+		{"zip member", "out.jar",
+			makeZip(t, []testMember{{name: "build.properties", content: "date=2026-10-15\n", mtime: epoch}}),
+			makeZip(t, []testMember{{name: "build.properties", content: "date=2026-10-16\n", mtime: epoch}}),
+			nil, []string{likelyTimestamp}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyContent(tt.path, tt.a, tt.b, tt.ids); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLikelyCauses(t *testing.T) {
+	got, err := parseLikelyCauses("timestamp, random")
+	if err != nil || !slices.Equal(got, []string{likelyTimestamp, likelyRandom}) {
+		t.Errorf("got %v, %v", got, err)
+	}
+	if _, err := parseLikelyCauses("cosmic_rays"); err == nil {
+		t.Error("expected an error for an unknown cause")
+	}
+}
+
+func TestLikelyCause_Report(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "cache")
+	var log1Execs, log2Execs []*pb.SpawnExec
+	for _, out := range []struct{ path, a, b string }{
+		{"out/date.txt", "Thu Oct 16 12:00:01 UTC 2026\n", "Thu Oct 16 12:00:09 UTC 2026\n"},
+		{"out/user.txt", "user=alice\n", "user=bob\n"},
+		{"out/version.txt", "1\n", "2\n"},
+	} {
+		a := genrule(out.path, "")
+		a.ActualOutputs[0].Digest = writeBlob(t, cache, out.a)
+		b := genrule(out.path, "")
+		b.ActualOutputs[0].Digest = writeBlob(t, cache, out.b)
+		log1Execs = append(log1Execs, a)
+		log2Execs = append(log2Execs, b)
+	}
+	log1 := writeLogs(t, dir, "log1.bin", log1Execs)
+	log2 := writeLogs(t, dir, "log2.bin", log2Execs)
+	paths := []string{log1, log2}
+
+	out := captureStdout(t, func() {
+		runWithOptions(paths, options{diskCache: cache})
+	})
+	for _, want := range []string{
+		"  out/date.txt [Genrule]\n    differs in: actual_outputs (likely: timestamp)\n",
+		"  out/user.txt [Genrule]\n    differs in: actual_outputs (likely: user_or_host)\n",
+		"  out/version.txt [Genrule]\n    differs in: actual_outputs (likely: unknown)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	var code int
+	out = captureStdout(t, func() {
+		code = runWithOptions(paths, options{diskCache: cache, likelyCauses: []string{likelyTimestamp}})
+	})
+	if code != exitNonDeterministic {
+		t.Errorf("got exit code %d, want %d", code, exitNonDeterministic)
+	}
+	if !strings.Contains(out, "out/date.txt") || strings.Contains(out, "out/user.txt") {
+		t.Errorf("expected only the timestamp action:\n%s", out)
+	}
+	if !strings.Contains(out, "(2 action(s) with other likely causes not shown)") {
+		t.Errorf("expected a note on filtered actions:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		runWithOptions(paths, options{diskCache: cache, groupByLikelyCause: true})
+	})
+	date := strings.Index(out, "\nLikely cause: timestamp: 1\n  out/date.txt")
+	user := strings.Index(out, "\nLikely cause: user_or_host: 1\n  out/user.txt")
+	unknown := strings.Index(out, "\nLikely cause: unknown: 1\n  out/version.txt")
+	if date < 0 || user < date || unknown < user {
+		t.Errorf("expected groups by likely cause in order:\n%s", out)
+	}
+	if strings.Contains(out, "Origins of non-determinism") {
+		t.Errorf("expected no origin group when grouping by likely cause:\n%s", out)
+	}
+
+	if code := runWithOptions(paths, options{groupByLikelyCause: true}); code != exitUsageError {
+		t.Errorf("grouping without content: got exit code %d, want %d", code, exitUsageError)
+	}
+}
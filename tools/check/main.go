@@ -3,8 +3,13 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"time"
 
 	execlog "tools/execlog/lib"
 	pb "tools/execlog/proto"
@@ -76,35 +81,84 @@ func diffSections(a, b *pb.SpawnExec) []string {
 	return diffs
 }
 
-// formatDigest returns a short string describing a file's digest.
-func formatDigest(d *pb.Digest) string {
+// change is one difference within a section, from the reference action to
+// the differing one. It is also part of the JSON report.
+type change struct {
+	// Kind is "added", "removed" or "changed".
+	Kind string `json:"kind"`
+	// Index is the position of a command argument in the reference, or in
+	// the outlier for added arguments. NewIndex is the position in the
+	// outlier of a changed argument, if it moved.
+	Index    *int `json:"index,omitempty"`
+	NewIndex *int `json:"new_index,omitempty"`
+	// Flag is the flag whose value changed, was added or was removed.
+	Flag string `json:"flag,omitempty"`
+	// Name is the environment variable, platform property or path.
+	Name string `json:"name,omitempty"`
+	// Old and New are the values of an argument, flag, variable or property.
+	Old *string `json:"old,omitempty"`
+	New *string `json:"new,omitempty"`
+	// OldDigest and NewDigest are the digests of an input or output.
+	OldDigest *reportDigest `json:"old_digest,omitempty"`
+	NewDigest *reportDigest `json:"new_digest,omitempty"`
+}
+
+// Kinds of change.
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeChanged = "changed"
+)
+
+// reportDigest is the digest of a file in the JSON report.
+type reportDigest struct {
+	Hash      string `json:"hash"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+func newReportDigest(d *pb.Digest) *reportDigest {
 	if d == nil {
-		return "(no digest)"
+		return nil
 	}
-	return fmt.Sprintf("hash=%s size=%d", d.Hash, d.SizeBytes)
+	return &reportDigest{Hash: d.Hash, SizeBytes: d.SizeBytes}
 }
 
-// diffCommandArgs returns detail lines describing how command_args differ.
-func diffCommandArgs(a, b *pb.SpawnExec) []string {
-	var lines []string
-	max := len(a.CommandArgs)
-	if len(b.CommandArgs) > max {
-		max = len(b.CommandArgs)
+// valueChanges compares two name/value maps, in name order.
+func valueChanges(aMap, bMap map[string]string) []change {
+	names := make(map[string]bool)
+	for name := range aMap {
+		names[name] = true
+	}
+	for name := range bMap {
+		names[name] = true
 	}
-	for i := 0; i < max; i++ {
-		if i >= len(a.CommandArgs) {
-			lines = append(lines, fmt.Sprintf("  added [%d]: %q", i, b.CommandArgs[i]))
-		} else if i >= len(b.CommandArgs) {
-			lines = append(lines, fmt.Sprintf("  removed [%d]: %q", i, a.CommandArgs[i]))
-		} else if a.CommandArgs[i] != b.CommandArgs[i] {
-			lines = append(lines, fmt.Sprintf("  changed [%d]: %q -> %q", i, a.CommandArgs[i], b.CommandArgs[i]))
+	var changes []change
+	for _, name := range sortedKeys(names) {
+		va, inA := aMap[name]
+		vb, inB := bMap[name]
+		switch {
+		case !inB:
+			changes = append(changes, change{Kind: changeRemoved, Name: name, Old: &va})
+		case !inA:
+			changes = append(changes, change{Kind: changeAdded, Name: name, New: &vb})
+		case va != vb:
+			changes = append(changes, change{Kind: changeChanged, Name: name, Old: &va, New: &vb})
 		}
 	}
-	return lines
+	return changes
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
-// diffEnvVars returns detail lines describing how environment_variables differ.
-func diffEnvVars(a, b *pb.SpawnExec) []string {
+// envChanges compares environment_variables.
+func envChanges(a, b *pb.SpawnExec) []change {
 	aMap := make(map[string]string)
 	for _, e := range a.EnvironmentVariables {
 		aMap[e.Name] = e.Value
@@ -113,26 +167,24 @@ func diffEnvVars(a, b *pb.SpawnExec) []string {
 	for _, e := range b.EnvironmentVariables {
 		bMap[e.Name] = e.Value
 	}
+	return valueChanges(aMap, bMap)
+}
 
-	var lines []string
-	for name, va := range aMap {
-		vb, ok := bMap[name]
-		if !ok {
-			lines = append(lines, fmt.Sprintf("  removed: %s=%q", name, va))
-		} else if va != vb {
-			lines = append(lines, fmt.Sprintf("  changed: %s=%q -> %q", name, va, vb))
-		}
+// platformChanges compares platform properties.
+func platformChanges(a, b *pb.SpawnExec) []change {
+	aMap := make(map[string]string)
+	for _, p := range a.Platform.GetProperties() {
+		aMap[p.Name] = p.Value
 	}
-	for name, vb := range bMap {
-		if _, ok := aMap[name]; !ok {
-			lines = append(lines, fmt.Sprintf("  added: %s=%q", name, vb))
-		}
+	bMap := make(map[string]string)
+	for _, p := range b.Platform.GetProperties() {
+		bMap[p.Name] = p.Value
 	}
-	return lines
+	return valueChanges(aMap, bMap)
 }
 
-// diffFiles returns detail lines describing how a file list (inputs or actual_outputs) differs.
-func diffFiles(aFiles, bFiles []*pb.File) []string {
+// fileChanges compares a file list (inputs or actual_outputs), in path order.
+func fileChanges(aFiles, bFiles []*pb.File) []change {
 	aMap := make(map[string]*pb.Digest)
 	for _, f := range aFiles {
 		aMap[f.Path] = f.Digest
@@ -141,26 +193,31 @@ func diffFiles(aFiles, bFiles []*pb.File) []string {
 	for _, f := range bFiles {
 		bMap[f.Path] = f.Digest
 	}
-
-	var lines []string
-	for path, da := range aMap {
-		db, ok := bMap[path]
-		if !ok {
-			lines = append(lines, fmt.Sprintf("  removed: %s (%s)", path, formatDigest(da)))
-		} else if !proto.Equal(da, db) {
-			lines = append(lines, fmt.Sprintf("  changed: %s (%s -> %s)", path, formatDigest(da), formatDigest(db)))
-		}
+	paths := make(map[string]bool)
+	for path := range aMap {
+		paths[path] = true
+	}
+	for path := range bMap {
+		paths[path] = true
 	}
-	for path, db := range bMap {
-		if _, ok := aMap[path]; !ok {
-			lines = append(lines, fmt.Sprintf("  added: %s (%s)", path, formatDigest(db)))
+	var changes []change
+	for _, path := range sortedKeys(paths) {
+		da, inA := aMap[path]
+		db, inB := bMap[path]
+		switch {
+		case !inB:
+			changes = append(changes, change{Kind: changeRemoved, Name: path, OldDigest: newReportDigest(da)})
+		case !inA:
+			changes = append(changes, change{Kind: changeAdded, Name: path, NewDigest: newReportDigest(db)})
+		case !proto.Equal(da, db):
+			changes = append(changes, change{Kind: changeChanged, Name: path, OldDigest: newReportDigest(da), NewDigest: newReportDigest(db)})
 		}
 	}
-	return lines
+	return changes
 }
 
-// diffListedOutputs returns detail lines describing how listed_outputs differ.
-func diffListedOutputs(a, b *pb.SpawnExec) []string {
+// listedOutputChanges compares listed_outputs as sets.
+func listedOutputChanges(a, b *pb.SpawnExec) []change {
 	aSet := make(map[string]bool)
 	for _, o := range a.ListedOutputs {
 		aSet[o] = true
@@ -169,260 +226,1049 @@ func diffListedOutputs(a, b *pb.SpawnExec) []string {
 	for _, o := range b.ListedOutputs {
 		bSet[o] = true
 	}
-
-	var lines []string
-	for o := range aSet {
+	var changes []change
+	for _, o := range sortedKeys(aSet) {
 		if !bSet[o] {
-			lines = append(lines, fmt.Sprintf("  removed: %s", o))
+			changes = append(changes, change{Kind: changeRemoved, Name: o})
 		}
 	}
-	for o := range bSet {
+	for _, o := range sortedKeys(bSet) {
 		if !aSet[o] {
-			lines = append(lines, fmt.Sprintf("  added: %s", o))
-		}
-	}
-	return lines
-}
-
-// diffPlatform returns detail lines describing how platform properties differ.
-func diffPlatform(a, b *pb.SpawnExec) []string {
-	aMap := make(map[string]string)
-	if a.Platform != nil {
-		for _, p := range a.Platform.Properties {
-			aMap[p.Name] = p.Value
-		}
-	}
-	bMap := make(map[string]string)
-	if b.Platform != nil {
-		for _, p := range b.Platform.Properties {
-			bMap[p.Name] = p.Value
+			changes = append(changes, change{Kind: changeAdded, Name: o})
 		}
 	}
-
-	var lines []string
-	for name, va := range aMap {
-		vb, ok := bMap[name]
-		if !ok {
-			lines = append(lines, fmt.Sprintf("  removed: %s=%q", name, va))
-		} else if va != vb {
-			lines = append(lines, fmt.Sprintf("  changed: %s=%q -> %q", name, va, vb))
-		}
-	}
-	for name, vb := range bMap {
-		if _, ok := aMap[name]; !ok {
-			lines = append(lines, fmt.Sprintf("  added: %s=%q", name, vb))
-		}
-	}
-	return lines
+	return changes
 }
 
-// verboseDetails returns detail lines for a given section name.
-func verboseDetails(section string, a, b *pb.SpawnExec) []string {
+// sectionChanges returns the changes within a given section name.
+func sectionChanges(section string, a, b *pb.SpawnExec) []change {
 	switch section {
 	case "command_args":
-		return diffCommandArgs(a, b)
+		return commandArgChanges(a, b)
 	case "environment_variables":
-		return diffEnvVars(a, b)
+		return envChanges(a, b)
 	case "platform":
-		return diffPlatform(a, b)
+		return platformChanges(a, b)
 	case "inputs":
-		return diffFiles(a.Inputs, b.Inputs)
+		return fileChanges(a.Inputs, b.Inputs)
 	case "listed_outputs":
-		return diffListedOutputs(a, b)
+		return listedOutputChanges(a, b)
 	case "actual_outputs":
-		return diffFiles(a.ActualOutputs, b.ActualOutputs)
+		return fileChanges(a.ActualOutputs, b.ActualOutputs)
 	}
 	return nil
 }
 
-// actionKey returns the pairing key for a SpawnExec (first listed output).
-func actionKey(exec *pb.SpawnExec) string {
-	return execlog.GetFirstOutput(exec)
+// formatDigest returns a short string describing a file's digest.
+func formatDigest(d *reportDigest) string {
+	if d == nil {
+		return "(no digest)"
+	}
+	return fmt.Sprintf("hash=%s size=%d", d.Hash, d.SizeBytes)
 }
 
-// run is the testable entry point. It returns an exit code.
-func run(paths []string, runner string, verbose bool) int {
-	if len(paths) != 2 {
-		fmt.Fprintf(os.Stderr, "Error: exactly two --log_path values required, got %d\n", len(paths))
-		return exitUsageError
+// formatChange formats a change of section as a detail line. Command
+// argument changes are formatted by commandArgDetails.
+func formatChange(section string, c change) string {
+	switch section {
+	case "inputs", "actual_outputs":
+		switch c.Kind {
+		case changeAdded:
+			return fmt.Sprintf("  added: %s (%s)", c.Name, formatDigest(c.NewDigest))
+		case changeRemoved:
+			return fmt.Sprintf("  removed: %s (%s)", c.Name, formatDigest(c.OldDigest))
+		}
+		return fmt.Sprintf("  changed: %s (%s -> %s)", c.Name, formatDigest(c.OldDigest), formatDigest(c.NewDigest))
+	case "listed_outputs":
+		return fmt.Sprintf("  %s: %s", c.Kind, c.Name)
 	}
+	switch c.Kind {
+	case changeAdded:
+		return fmt.Sprintf("  added: %s=%q", c.Name, *c.New)
+	case changeRemoved:
+		return fmt.Sprintf("  removed: %s=%q", c.Name, *c.Old)
+	}
+	return fmt.Sprintf("  changed: %s=%q -> %q", c.Name, *c.Old, *c.New)
+}
 
-	// Phase 1: Parse log1 → collect all SpawnExec, build Golden.
-	f1, err := os.Open(paths[0])
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening %s: %v\n", paths[0], err)
-		return exitUsageError
+// verboseDetails returns detail lines for a given section name.
+func verboseDetails(section string, a, b *pb.SpawnExec) []string {
+	if section == "command_args" {
+		return commandArgDetails(a, b)
+	}
+	var lines []string
+	for _, c := range sectionChanges(section, a, b) {
+		lines = append(lines, formatChange(section, c))
 	}
-	defer f1.Close()
+	return lines
+}
 
-	parser1 := execlog.NewFilteringParser(f1, runner)
-	golden := execlog.NewGolden()
-	log1Actions := make(map[string]*pb.SpawnExec)
+// decodeLog reads every SpawnExec of the log at path, in log order, with
+// paths normalized first if a normalizer is given. It also returns the
+// warning about fields unknown to this version of spawn.proto, if any.
+func decodeLog(path, runner string, normalizer *execlog.Normalizer) ([]*pb.SpawnExec, string, error) {
+	log, err := execlog.OpenLog(path, runner)
+	if err != nil {
+		return nil, "", fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer log.Close()
 
+	var parser execlog.Parser = log
+	if normalizer != nil {
+		parser = execlog.NewNormalizingParser(normalizer, parser)
+	}
+	var execs []*pb.SpawnExec
 	for {
-		exec, err := parser1.Next()
+		exec, err := parser.Next()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing %s: %v\n", paths[0], err)
-			return exitUsageError
+			return nil, "", fmt.Errorf("parsing %s: %w", path, err)
 		}
 		if exec == nil {
 			break
 		}
-		golden.AddSpawnExec(exec)
-		key := actionKey(exec)
-		if key != "" {
-			log1Actions[key] = exec
-		}
+		execs = append(execs, exec)
 	}
+	return execs, log.UnknownFieldsWarning(), nil
+}
 
-	// Phase 2: Parse log2 with reordering.
-	f2, err := os.Open(paths[1])
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening %s: %v\n", paths[1], err)
-		return exitUsageError
+// sliceParser replays decoded SpawnExecs.
+type sliceParser []*pb.SpawnExec
+
+func (p *sliceParser) Next() (*pb.SpawnExec, error) {
+	if len(*p) == 0 {
+		return nil, nil
 	}
-	defer f2.Close()
+	exec := (*p)[0]
+	*p = (*p)[1:]
+	return exec, nil
+}
 
-	parser2 := execlog.NewFilteringParser(f2, runner)
-	reordered, err := execlog.NewReorderingParser(golden, parser2)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing %s: %v\n", paths[1], err)
-		return exitUsageError
+// keyLog maps the decoded actions of the log at path from action key, as
+// computed by the pairing strategy of golden, to the SpawnExecs with that
+// key, in log order. The first log records its order in golden; later logs
+// are read through a ReorderingParser so they are consumed in the same
+// order.
+func keyLog(path string, execs []*pb.SpawnExec, golden *execlog.Golden, first bool) (map[string][]*pb.SpawnExec, error) {
+	parser := execlog.Parser((*sliceParser)(&execs))
+	if !first {
+		var err error
+		parser, err = execlog.NewReorderingParser(golden, parser)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
 	}
 
-	log2Actions := make(map[string]*pb.SpawnExec)
+	keyer := golden.Strategy().NewKeyer()
+	actions := make(map[string][]*pb.SpawnExec)
 	for {
-		exec, err := reordered.Next()
+		exec, err := parser.Next()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading reordered %s: %v\n", paths[1], err)
-			return exitUsageError
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
 		}
 		if exec == nil {
 			break
 		}
-		key := actionKey(exec)
+		if first {
+			golden.AddSpawnExec(exec)
+		}
+		key := keyer.Key(exec)
 		if key != "" {
-			log2Actions[key] = exec
+			actions[key] = append(actions[key], exec)
 		}
 	}
+	return actions, nil
+}
 
-	// Phase 3: Compare paired actions.
-	type diffResult struct {
-		key         string
-		mnemonic    string
-		targetLabel string
-		sections    []string
-		a, b        *pb.SpawnExec
+// loadActions parses every log into memory, decoding up to opts.jobs logs
+// at once, and calls compare with the actions of every key, in key order.
+// The first log builds the Golden ordering, the others are reordered to
+// match it.
+func loadActions(paths []string, opts options, compare func(key string, execs []*pb.SpawnExec)) ([]duplicateKey, error) {
+	decoded := make([][]*pb.SpawnExec, len(paths))
+	warnings := make([]string, len(paths))
+	errs := make([]error, len(paths))
+	forEach(opts.jobs, len(paths), func(i int) {
+		decoded[i], warnings[i], errs[i] = decodeLog(paths[i], opts.runner, opts.normalizer)
+	})
+	for i, path := range paths {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if warnings[i] != "" {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", path, warnings[i])
+		}
 	}
 
-	var nonDeterministic []diffResult
-	var skippedCount int
-	var uniqueToLog1 []string
-	var uniqueToLog2 []string
-
-	for key, a := range log1Actions {
-		b, ok := log2Actions[key]
-		if !ok {
-			uniqueToLog1 = append(uniqueToLog1, key)
-			continue
+	golden := execlog.NewGoldenWithStrategy(opts.pairBy)
+	loaded := make([]map[string][]*pb.SpawnExec, len(paths))
+	for i, path := range paths {
+		actions, err := keyLog(path, decoded[i], golden, i == 0)
+		if err != nil {
+			return nil, err
 		}
+		loaded[i] = actions
+		decoded[i] = nil
+	}
 
-		// Fast path: proto.Equal skips detailed comparison.
-		if proto.Equal(a, b) {
-			continue
+	logs, duplicates := resolveDuplicates(loaded)
+	keySet := make(map[string]bool)
+	for _, actions := range logs {
+		for key := range actions {
+			keySet[key] = true
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		execs := make([]*pb.SpawnExec, len(logs))
+		for i, actions := range logs {
+			execs[i] = actions[key]
+		}
+		compare(key, execs)
+	}
+	return duplicates, nil
+}
+
+// diffResult describes one non-deterministic action. a is the version most
+// logs agree on and b the first version that differs from it.
+type diffResult struct {
+	key         string
+	mnemonic    string
+	targetLabel string
+	sections    []string
+	a, b        *pb.SpawnExec
+
+	// majority and outliers are indexes into the compared logs. outlierExecs
+	// holds the SpawnExec of each outlier, in the same order. noMajority is
+	// set when more than two logs are compared and no group of equal
+	// versions outnumbers every other group; majority is then the group
+	// of the earliest log, which serves as the reference.
+	majority     []int
+	outliers     []int
+	outlierExecs []*pb.SpawnExec
+	noMajority   bool
+
+	// cause is causeOrigin or causePropagated. For propagated actions,
+	// upstream lists the keys of the actions whose changed outputs they consume.
+	cause    string
+	upstream []string
+
+	// suppressedBy is the --config suppression that matched, if any.
+	suppressedBy *suppression
+
+	// orderingOnly is set in --semantic mode when the action differs only in
+	// the order of its command arguments.
+	orderingOnly bool
+
+	// likelyCauses guesses why the outputs differ, from their content. It is
+	// only set when a blob store is available.
+	likelyCauses []string
+}
+
+// comparison is the outcome of comparing the actions of one key.
+type comparison struct {
+	key string
+	// execs[i] is the action in log i, or nil; present lists the logs
+	// that have it.
+	execs   []*pb.SpawnExec
+	present []int
+	// majority and others are the differing versions of the action, for
+	// the producer graph; majority is nil if all versions are equal.
+	majority *pb.SpawnExec
+	others   []*pb.SpawnExec
+	// action is the passed or skipped action; diff is set instead if the
+	// action is non-deterministic.
+	action  pairedAction
+	skipped bool
+	diff    *diffResult
+}
+
+// compareAction compares the actions of key across logs, execs[i] being the
+// action in log i or nil. It is safe to call concurrently.
+func compareAction(key string, execs []*pb.SpawnExec, opts options, store blobStore) comparison {
+	c := comparison{key: key, execs: execs}
+	for i, exec := range execs {
+		if exec != nil {
+			c.present = append(c.present, i)
+		}
+	}
+	if len(c.present) == 1 {
+		return c
+	}
+
+	if opts.semantic {
+		for _, i := range c.present {
+			execs[i] = canonicalize(execs[i])
+		}
+	}
+
+	// Fast path: proto.Equal skips detailed comparison.
+	groups := groupEqual(c.present, execs)
+	if len(groups) == 1 {
+		c.action = newPairedAction(key, execs[c.present[0]])
+		return c
+	}
+
+	best := majorityGroup(groups)
+	majority := groups[best]
+	a := execs[majority[0]]
+	c.majority = a
+	for _, i := range c.present {
+		if !proto.Equal(a, execs[i]) {
+			c.others = append(c.others, execs[i])
 		}
+	}
+	c.action = newPairedAction(key, a)
+
+	// Only report non-determinism for remotable or cacheable actions.
+	if !a.Remotable && !a.Cacheable {
+		c.skipped = true
+		return c
+	}
 
-		// Only report non-determinism for remotable or cacheable actions.
-		if !a.Remotable && !a.Cacheable {
-			skippedCount++
+	inMajority := make(map[int]bool)
+	for _, i := range majority {
+		inMajority[i] = true
+	}
+	var outliers []int
+	var outlierExecs []*pb.SpawnExec
+	var sectionLists [][]string
+	for _, i := range c.present {
+		if inMajority[i] {
 			continue
 		}
+		outliers = append(outliers, i)
+		outlierExecs = append(outlierExecs, execs[i])
+		sections := diffSections(a, execs[i])
+		if store != nil && paramsDiffer(store, majority[0], a, i, execs[i]) {
+			sections = append(sections, paramsSection)
+		}
+		sectionLists = append(sectionLists, sections)
+	}
+
+	sections := mergeSections(sectionLists...)
+	if len(sections) == 0 {
+		return c
+	}
+	d := &diffResult{
+		key:          key,
+		mnemonic:     c.action.mnemonic,
+		targetLabel:  c.action.targetLabel,
+		sections:     sections,
+		a:            a,
+		b:            outlierExecs[0],
+		majority:     majority,
+		outliers:     outliers,
+		outlierExecs: outlierExecs,
+		noMajority:   len(c.present) > 2 && !isMajority(groups, best),
+	}
+	if opts.semantic {
+		d.orderingOnly = orderingOnly(d)
+	}
+	if store != nil {
+		d.likelyCauses = likelyCauses(store, d)
+	}
+	c.diff = d
+	return c
+}
 
-		sections := diffSections(a, b)
-		if len(sections) > 0 {
-			mnemonic := a.Mnemonic
-			if mnemonic == "" {
-				mnemonic = "(unknown)"
+// groupEqual partitions the logs that contain an action into groups of equal
+// SpawnExecs, ordered by the first log index in each group.
+func groupEqual(present []int, execs []*pb.SpawnExec) [][]int {
+	var groups [][]int
+	for _, i := range present {
+		placed := false
+		for g, group := range groups {
+			if proto.Equal(execs[group[0]], execs[i]) {
+				groups[g] = append(groups[g], i)
+				placed = true
+				break
 			}
-			nonDeterministic = append(nonDeterministic, diffResult{
-				key:         key,
-				mnemonic:    mnemonic,
-				targetLabel: a.TargetLabel,
-				sections:    sections,
-				a:           a,
-				b:           b,
-			})
+		}
+		if !placed {
+			groups = append(groups, []int{i})
 		}
 	}
+	return groups
+}
 
-	for key := range log2Actions {
-		if _, ok := log1Actions[key]; !ok {
-			uniqueToLog2 = append(uniqueToLog2, key)
+// majorityGroup returns the index of the largest group. Ties go to the group
+// containing the earliest log, so with two logs log1 is the reference.
+func majorityGroup(groups [][]int) int {
+	best := 0
+	for g := range groups {
+		if len(groups[g]) > len(groups[best]) {
+			best = g
 		}
 	}
+	return best
+}
 
-	// Phase 4: Print report.
-	if len(nonDeterministic) > 0 {
-		fmt.Printf("Non-deterministic actions found: %d\n\n", len(nonDeterministic))
-		for _, d := range nonDeterministic {
-			if d.targetLabel != "" {
-				fmt.Printf("  %s [%s] (%s)\n", d.key, d.mnemonic, d.targetLabel)
-			} else {
-				fmt.Printf("  %s [%s]\n", d.key, d.mnemonic)
+// isMajority reports whether groups[best] is larger than every other group.
+func isMajority(groups [][]int, best int) bool {
+	for g := range groups {
+		if g != best && len(groups[g]) >= len(groups[best]) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeSections returns the union of section lists, in diffSections order.
+func mergeSections(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, s := range list {
+			seen[s] = true
+		}
+	}
+	var merged []string
+	for _, s := range sectionOrder {
+		if seen[s] {
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
+
+// sectionOrder lists the sections reported by diffSections, and
+// paramsSection, in order.
+var sectionOrder = []string{
+	"command_args",
+	paramsSection,
+	"environment_variables",
+	"platform",
+	"inputs",
+	"listed_outputs",
+	"actual_outputs",
+}
+
+// logNames formats log indexes as "log1, log3".
+func logNames(indexes []int) string {
+	names := make([]string, len(indexes))
+	for i, idx := range indexes {
+		names[i] = fmt.Sprintf("log%d", idx+1)
+	}
+	return strings.Join(names, ", ")
+}
+
+// options holds the settings of a check run.
+type options struct {
+	runner      string
+	verbose     bool
+	originsOnly bool
+	configPath  string
+	diskCache   string
+	// outputTrees holds one execroot copy per log to diff outputs from.
+	outputTrees []string
+	// semantic compares inputs, outputs, environment and platform as sets
+	// and reports command argument reorderings as ordering-only.
+	semantic bool
+	// normalizer rewrites paths before actions are keyed; nil disables it.
+	normalizer *execlog.Normalizer
+	// pairBy selects the keys that pair actions across logs; "" pairs by
+	// first listed output.
+	pairBy execlog.PairingStrategy
+	// streaming sorts the logs by key on disk and merge-joins them instead
+	// of loading them into memory.
+	streaming bool
+	// tempDir holds the temporary files of --streaming; "" is the default
+	// temporary directory.
+	tempDir string
+	// jobs is the number of logs decoded and actions compared at once;
+	// values below 1 mean one.
+	jobs int
+	// now decides which suppressions have expired; zero means time.Now.
+	now time.Time
+	// likelyCauses only reports actions with one of these likely causes.
+	likelyCauses []string
+	// groupByLikelyCause groups the report by likely cause instead of by
+	// origin and propagated.
+	groupByLikelyCause bool
+	// outputFormat is "text" (the default), "json" or "markdown".
+	outputFormat string
+	// jsonReport, if set, is a path to also write the JSON report to.
+	jsonReport string
+	// junitReport, if set, is a path to write a JUnit XML report to, with
+	// one test case per action or, with junitGroupBy "target", per target.
+	junitReport  string
+	junitGroupBy string
+	// htmlReport, if set, is a path to write an HTML report to.
+	htmlReport string
+	// sarifReport, if set, is a path to write a SARIF report to. Findings
+	// are located on BUILD files under workspace, "." if empty.
+	sarifReport string
+	workspace   string
+}
+
+// resultGroup is a titled group of actions in the text report.
+type resultGroup struct {
+	title   string
+	results []diffResult
+	hidden  bool
+}
+
+// formatDiffResult returns the lines of the text report for one
+// non-deterministic action. With a blob store, the content of differing
+// outputs is diffed too.
+func formatDiffResult(d diffResult, verbose bool, store blobStore, nWay bool) []string {
+	var lines []string
+	if d.targetLabel != "" {
+		lines = append(lines, fmt.Sprintf("  %s [%s] (%s)", d.key, d.mnemonic, d.targetLabel))
+	} else {
+		lines = append(lines, fmt.Sprintf("  %s [%s]", d.key, d.mnemonic))
+	}
+	var notes string
+	if d.orderingOnly {
+		notes += " (ordering only)"
+	}
+	if len(d.likelyCauses) > 0 {
+		notes += fmt.Sprintf(" (likely: %s)", strings.Join(d.likelyCauses, ", "))
+	}
+	lines = append(lines, fmt.Sprintf("    differs in: %s%s", strings.Join(d.sections, ", "), notes))
+	if d.cause == causePropagated {
+		lines = append(lines, fmt.Sprintf("    root cause: propagated from %s", strings.Join(d.upstream, ", ")))
+	} else {
+		lines = append(lines, "    root cause: origin")
+	}
+	if nWay {
+		switch {
+		case d.noMajority && len(d.majority) == 1:
+			lines = append(lines, "    no majority: every log produced a different result")
+		case d.noMajority:
+			lines = append(lines, fmt.Sprintf("    no majority: reference: %s; others: %s", logNames(d.majority), logNames(d.outliers)))
+		default:
+			lines = append(lines, fmt.Sprintf("    majority: %s; outliers: %s", logNames(d.majority), logNames(d.outliers)))
+		}
+	}
+	if !verbose && store == nil {
+		return lines
+	}
+	for i, outlier := range d.outlierExecs {
+		indent := "    "
+		if nWay {
+			lines = append(lines, fmt.Sprintf("    %s vs %s:", logNames(d.outliers[i:i+1]), logNames(d.majority[:1])))
+			indent = "      "
+		}
+		for _, section := range d.sections {
+			var details []string
+			switch {
+			case verbose && section == paramsSection:
+				details = paramsDetails(store, d.majority[0], d.a, d.outliers[i], outlier)
+			case verbose:
+				details = verboseDetails(section, d.a, outlier)
+			}
+			if len(details) > 0 {
+				lines = append(lines, fmt.Sprintf("%s%s:", indent, section))
+				for _, line := range details {
+					lines = append(lines, fmt.Sprintf("%s  %s", indent, line))
+				}
 			}
-			fmt.Printf("    differs in: %s\n", strings.Join(d.sections, ", "))
-			if verbose {
-				for _, section := range d.sections {
-					details := verboseDetails(section, d.a, d.b)
-					if len(details) > 0 {
-						fmt.Printf("    %s:\n", section)
-						for _, line := range details {
-							fmt.Printf("      %s\n", line)
-						}
+			if section == "actual_outputs" && store != nil {
+				if diffs := outputContentDiffs(store, d.majority[0], d.a, d.outliers[i], outlier); len(diffs) > 0 {
+					lines = append(lines, fmt.Sprintf("%scontent (from %s):", indent, store.name()))
+					for _, line := range diffs {
+						lines = append(lines, fmt.Sprintf("%s  %s", indent, line))
 					}
 				}
 			}
 		}
+	}
+	return lines
+}
+
+// categoryCounts formats the number of actions per category, e.g.
+// "2 origin, 1 propagated". Ordering-only actions are only counted in
+// --semantic mode.
+func categoryCounts(origins, propagated, ordering int, semantic bool) string {
+	counts := fmt.Sprintf("%d origin, %d propagated", origins, propagated)
+	if semantic {
+		counts += fmt.Sprintf(", %d ordering-only", ordering)
+	}
+	return counts
+}
+
+// run is the testable entry point. It returns an exit code.
+func run(paths []string, runner string, verbose bool) int {
+	return runWithOptions(paths, options{runner: runner, verbose: verbose})
+}
+
+// runWithOptions is run with the full set of options.
+func runWithOptions(paths []string, opts options) int {
+	if len(paths) < 2 {
+		fmt.Fprintf(os.Stderr, "Error: at least two --log_path values required, got %d\n", len(paths))
+		return exitUsageError
+	}
+	switch opts.outputFormat {
+	case "", "text", "json", "markdown":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown --output_format %q, want text, json or markdown\n", opts.outputFormat)
+		return exitUsageError
+	}
+	switch opts.junitGroupBy {
+	case "", junitByAction, junitByTarget:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown --junit_group_by %q, want %s or %s\n", opts.junitGroupBy, junitByAction, junitByTarget)
+		return exitUsageError
+	}
+
+	var cfg *config
+	if opts.configPath != "" {
+		var err error
+		cfg, err = loadConfig(opts.configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			return exitUsageError
+		}
+	}
+	now := opts.now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	suppressions := newSuppressor(cfg, now)
+
+	var stores multiStore
+	if opts.diskCache != "" {
+		if info, err := os.Stat(filepath.Join(opts.diskCache, "cas")); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: %s is not a Bazel disk cache (no cas directory)\n", opts.diskCache)
+			return exitUsageError
+		}
+		stores = append(stores, diskCache{dir: opts.diskCache})
+	}
+	if len(opts.outputTrees) > 0 {
+		if len(opts.outputTrees) != len(paths) {
+			fmt.Fprintf(os.Stderr, "Error: got %d --output_tree values for %d --log_path values\n", len(opts.outputTrees), len(paths))
+			return exitUsageError
+		}
+		stores = append(stores, outputTree{roots: opts.outputTrees})
+	}
+	var store blobStore
+	switch len(stores) {
+	case 0:
+	case 1:
+		store = stores[0]
+	default:
+		store = stores
+	}
+	if store == nil && (len(opts.likelyCauses) > 0 || opts.groupByLikelyCause) {
+		fmt.Fprintf(os.Stderr, "Error: likely causes are guessed from output content and require --disk_cache or --output_tree\n")
+		return exitUsageError
+	}
+
+	// Phase 3: Compare paired actions. The actions of each key are compared
+	// on a pool of opts.jobs workers as the logs are paired below, and the
+	// results recorded in key order.
+	var nonDeterministic []diffResult
+	var passed, skipped []pairedAction
+	var totalPaired int
+	uniqueTo := make([][]string, len(paths))
+	// With --streaming, unique actions are spilled to disk until fuzzy
+	// pairing reads back the few it pairs.
+	uniqueActions := make([]map[string]uniqueAction, len(paths))
+	for i := range uniqueActions {
+		uniqueActions[i] = make(map[string]uniqueAction)
+	}
+	var spill *actionSpill
+	if opts.streaming {
+		var err error
+		if spill, err = newActionSpill(opts.tempDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitUsageError
+		}
+		defer spill.Close()
+	}
+	var spillErr error
+	missingFrom := make([][]string, len(paths))
+	outlierCounts := make([]int, len(paths))
+	producers := make(producerGraph)
+
+	record := func(c comparison) {
+		if len(c.present) == 1 {
+			i := c.present[0]
+			u, err := newUniqueAction(c.execs[i], spill)
+			if err != nil {
+				spillErr = err
+				return
+			}
+			uniqueTo[i] = append(uniqueTo[i], c.key)
+			uniqueActions[i][c.key] = u
+			return
+		}
+		for i := range c.execs {
+			if c.execs[i] == nil {
+				missingFrom[i] = append(missingFrom[i], c.key)
+			}
+		}
+		totalPaired++
+		if c.majority != nil {
+			producers.addChangedOutputs(c.key, c.majority, c.others)
+		}
+		switch {
+		case c.diff != nil:
+			nonDeterministic = append(nonDeterministic, *c.diff)
+		case c.skipped:
+			skipped = append(skipped, c.action)
+		case opts.junitReport != "":
+			// Only the JUnit report lists passing actions.
+			passed = append(passed, c.action)
+		}
+	}
+	pool := newComparePool(opts.jobs, func(key string, execs []*pb.SpawnExec) comparison {
+		return compareAction(key, execs, opts, store)
+	}, record)
+
+	// Phases 1 and 2: Parse every log and pair actions across all logs by
+	// key, telling apart actions that share a key. --streaming sorts the
+	// logs on disk rather than loading them.
+	var duplicates []duplicateKey
+	var err error
+	if opts.streaming {
+		duplicates, err = streamActions(paths, opts, pool.add)
+	} else {
+		duplicates, err = loadActions(paths, opts, pool.add)
+	}
+	pool.wait()
+	if err == nil {
+		err = spillErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error %v\n", err)
+		return exitUsageError
+	}
+
+	// Pair leftover unique actions that are probably the same action under
+	// a different key.
+	fuzzy, uniqueTo, err := fuzzyPairs(uniqueActions, uniqueTo, spill)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error %v\n", err)
+		return exitUsageError
+	}
+
+	// Phase 4: Label each action as an origin of non-determinism or as
+	// propagated from upstream, and set aside known non-determinism.
+	var origins, propagated, ordering []diffResult
+	var suppressed []diffResult
+	reported := nonDeterministic[:0]
+	for _, d := range nonDeterministic {
+		producers.classify(&d)
+		d.key, d.upstream = displayKey(d.key), displayKeys(d.upstream)
+		if d.suppressedBy = suppressions.suppress(&d); d.suppressedBy != nil {
+			suppressed = append(suppressed, d)
+			continue
+		}
+		switch {
+		case d.orderingOnly:
+			ordering = append(ordering, d)
+		case d.cause == causeOrigin:
+			origins = append(origins, d)
+		default:
+			propagated = append(propagated, d)
+		}
+		for _, i := range d.outliers {
+			outlierCounts[i]++
+		}
+		reported = append(reported, d)
+	}
+	nonDeterministic = reported
+	for _, actions := range [][]pairedAction{passed, skipped} {
+		for i := range actions {
+			actions[i].key = displayKey(actions[i].key)
+		}
+	}
+	for i := range uniqueTo {
+		displayKeys(uniqueTo[i])
+		displayKeys(missingFrom[i])
+	}
+	for i := range fuzzy {
+		fuzzy[i].keyA, fuzzy[i].keyB = displayKey(fuzzy[i].keyA), displayKey(fuzzy[i].keyB)
+	}
+
+	exitCode := exitDeterministic
+	if len(nonDeterministic) > 0 {
+		exitCode = exitNonDeterministic
+	}
+	result := &checkResult{
+		logs:           paths,
+		paired:         totalPaired,
+		passed:         passed,
+		skipped:        skipped,
+		reported:       nonDeterministic,
+		suppressed:     suppressed,
+		expired:        suppressions.expired(),
+		expiredMatches: suppressions.expiredMatches,
+		uniqueTo:       uniqueTo,
+		missingFrom:    missingFrom,
+		duplicates:     duplicates,
+		fuzzy:          fuzzy,
+		store:          store,
+	}
+	if opts.jsonReport != "" {
+		if err := writeReportFile(opts.jsonReport, result, writeJSONReport); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing JSON report: %v\n", err)
+			return exitUsageError
+		}
+	}
+	if opts.junitReport != "" {
+		err := writeReportFile(opts.junitReport, result, func(w io.Writer, r *checkResult) error {
+			return writeJUnitReport(w, r, opts.junitGroupBy)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing JUnit report: %v\n", err)
+			return exitUsageError
+		}
+	}
+	if opts.htmlReport != "" {
+		if err := writeReportFile(opts.htmlReport, result, writeHTMLReport); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing HTML report: %v\n", err)
+			return exitUsageError
+		}
+	}
+	if opts.sarifReport != "" {
+		workspace := opts.workspace
+		if workspace == "" {
+			workspace = "."
+		}
+		err := writeReportFile(opts.sarifReport, result, func(w io.Writer, r *checkResult) error {
+			return writeSARIFReport(w, r, workspace)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing SARIF report: %v\n", err)
+			return exitUsageError
+		}
+	}
+	switch opts.outputFormat {
+	case "json":
+		if err := writeJSONReport(os.Stdout, result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing JSON report: %v\n", err)
+			return exitUsageError
+		}
+		return exitCode
+	case "markdown":
+		if err := writeMarkdownReport(os.Stdout, result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing markdown report: %v\n", err)
+			return exitUsageError
+		}
+		return exitCode
+	}
+
+	// Phase 5: Print report, origins first.
+	nWay := len(paths) > 2
+	if len(nonDeterministic) > 0 {
+		fmt.Printf("Non-deterministic actions found: %d (%s)\n",
+			len(nonDeterministic), categoryCounts(len(origins), len(propagated), len(ordering), opts.semantic))
+		groups := []resultGroup{
+			{"Origins of non-determinism", origins, false},
+			{"Propagated from upstream actions", propagated, opts.originsOnly},
+			{"Ordering-only differences", ordering, false},
+		}
+		var otherCauses int
+		var visible []diffResult
+		for i := range groups {
+			if groups[i].hidden {
+				continue
+			}
+			var n int
+			groups[i].results, n = filterLikelyCauses(groups[i].results, opts.likelyCauses)
+			otherCauses += n
+			visible = append(visible, groups[i].results...)
+		}
+		if opts.groupByLikelyCause {
+			groups = groupByLikelyCause(visible)
+		}
+		for _, g := range groups {
+			if len(g.results) == 0 || g.hidden {
+				continue
+			}
+			fmt.Printf("\n%s: %d\n", g.title, len(g.results))
+			// Verbose and content diffs are slow, so format in parallel.
+			formatted := make([][]string, len(g.results))
+			forEach(opts.jobs, len(g.results), func(i int) {
+				formatted[i] = formatDiffResult(g.results[i], opts.verbose, store, nWay)
+			})
+			for _, lines := range formatted {
+				for _, line := range lines {
+					fmt.Println(line)
+				}
+			}
+		}
+		if opts.originsOnly && len(propagated) > 0 {
+			fmt.Printf("\n(%d propagated action(s) not shown)\n", len(propagated))
+		}
+		if otherCauses > 0 {
+			fmt.Printf("\n(%d action(s) with other likely causes not shown)\n", otherCauses)
+		}
 		fmt.Println()
 	}
 
-	if skippedCount > 0 {
-		fmt.Printf("Skipped %d non-remotable/non-cacheable differing action(s)\n", skippedCount)
+	if len(suppressed) > 0 {
+		fmt.Printf("Suppressed actions: %d\n", len(suppressed))
+		for _, d := range suppressed {
+			fmt.Printf("  %s [%s]\n", d.key, d.mnemonic)
+			fmt.Printf("    suppressed: %s (owner: %s)\n", d.suppressedBy.Reason, d.suppressedBy.Owner)
+		}
+		fmt.Println()
 	}
 
-	if len(uniqueToLog1) > 0 {
-		fmt.Printf("Actions unique to log1: %d\n", len(uniqueToLog1))
-		for _, k := range uniqueToLog1 {
-			fmt.Printf("  %s\n", k)
+	if len(result.expired) > 0 {
+		fmt.Printf("Expired suppressions: %d\n", len(result.expired))
+		for _, sup := range result.expired {
+			fmt.Printf("  %s (owner: %s, expired %s): %s\n", sup.describe(), sup.Owner, sup.Expires, sup.Reason)
+			if n := suppressions.expiredMatches[sup]; n > 0 {
+				fmt.Printf("    would have suppressed %d action(s)\n", n)
+			}
 		}
+		fmt.Println()
+	}
+
+	if len(duplicates) > 0 {
+		fmt.Printf("Duplicate action keys: %d\n", len(duplicates))
+		for _, dup := range duplicates {
+			fmt.Printf("  %s\n", formatDuplicate(dup))
+		}
+		fmt.Println()
 	}
 
-	if len(uniqueToLog2) > 0 {
-		fmt.Printf("Actions unique to log2: %d\n", len(uniqueToLog2))
-		for _, k := range uniqueToLog2 {
-			fmt.Printf("  %s\n", k)
+	if len(skipped) > 0 {
+		fmt.Printf("Skipped %d non-remotable/non-cacheable differing action(s)\n", len(skipped))
+	}
+
+	for i, unique := range uniqueTo {
+		if len(unique) > 0 {
+			fmt.Printf("Actions unique to log%d: %d\n", i+1, len(unique))
+			for _, k := range unique {
+				fmt.Printf("  %s\n", k)
+			}
+		}
+	}
+
+	if len(fuzzy) > 0 {
+		fmt.Printf("Probably paired actions: %d\n", len(fuzzy))
+		for _, p := range fuzzy {
+			for _, line := range formatFuzzyPair(p, opts.verbose) {
+				fmt.Println(line)
+			}
+		}
+	}
+
+	if nWay {
+		for i, missing := range missingFrom {
+			if len(missing) > 0 {
+				fmt.Printf("Actions missing from log%d: %d\n", i+1, len(missing))
+				for _, k := range missing {
+					fmt.Printf("  %s\n", k)
+				}
+			}
+		}
+
+		if len(nonDeterministic) > 0 {
+			fmt.Printf("\nOutliers by log:\n")
+			for i, count := range outlierCounts {
+				fmt.Printf("  log%d: %d action(s)\n", i+1, count)
+			}
 		}
 	}
 
 	// Summary line.
-	totalPaired := len(log1Actions) - len(uniqueToLog1)
-	fmt.Printf("\nSummary: %d paired actions compared, %d non-deterministic\n",
-		totalPaired, len(nonDeterministic))
+	var suppressedSummary string
+	if cfg != nil {
+		suppressedSummary = fmt.Sprintf(", %d suppressed", len(suppressed))
+	}
+	counts := categoryCounts(len(origins), len(propagated), len(ordering), opts.semantic)
+	if nWay {
+		fmt.Printf("\nSummary: %d paired actions compared across %d logs, %d non-deterministic (%s)%s\n",
+			totalPaired, len(paths), len(nonDeterministic), counts, suppressedSummary)
+	} else {
+		fmt.Printf("\nSummary: %d paired actions compared, %d non-deterministic (%s)%s\n",
+			totalPaired, len(nonDeterministic), counts, suppressedSummary)
+	}
+	return exitCode
+}
 
-	if len(nonDeterministic) > 0 {
-		return exitNonDeterministic
+// newNormalizer builds the path normalizer for the --path_rewrite rules and
+// --normalize_paths presets, or returns nil if there are none.
+func newNormalizer(rewrites []string, presets string) (*execlog.Normalizer, error) {
+	var rules []execlog.RewriteRule
+	for _, r := range rewrites {
+		rule, err := execlog.ParseRewriteRule(r)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	if presets != "" {
+		presetRules, err := execlog.PresetRules(strings.Split(presets, ","))
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, presetRules...)
 	}
-	return exitDeterministic
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return execlog.NewNormalizer(rules), nil
+}
+
+// pairingStrategyNames lists the values of --pair_by, e.g. "first_output,
+// outputs, target or digest".
+func pairingStrategyNames() string {
+	var names []string
+	for _, s := range execlog.PairingStrategies() {
+		names = append(names, string(s))
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
 }
 
 func main() {
 	var logPaths stringSlice
-	var runner string
-	var verbose bool
-	flag.Var(&logPaths, "log_path", "Input binary protobuf log file (must be specified exactly twice)")
-	flag.StringVar(&runner, "restrict_to_runner", "", "Filter to specific runner")
-	flag.BoolVar(&verbose, "verbose", false, "Print detailed differences for each non-deterministic action")
+	var outputTrees stringSlice
+	var pathRewrites stringSlice
+	var normalizePaths string
+	var likely string
+	var pairBy string
+	var opts options
+	flag.Var(&logPaths, "log_path", "Input execution log file, optionally gzip or zstd compressed (specify at least twice)")
+	flag.StringVar(&opts.runner, "restrict_to_runner", "", "Filter to specific runner")
+	flag.BoolVar(&opts.verbose, "verbose", false, "Print detailed differences for each non-deterministic action")
+	flag.BoolVar(&opts.originsOnly, "origins_only", false, "Only report actions where non-determinism originates, not those it propagated to")
+	flag.BoolVar(&opts.semantic, "semantic", false, "Compare inputs, outputs, environment and platform as sets; report reordered command arguments as ordering-only")
+	flag.StringVar(&opts.diskCache, "disk_cache", "", "Bazel --disk_cache directory to diff the content of differing outputs from")
+	flag.Var(&outputTrees, "output_tree", "Copy of a build's execroot to diff the content of differing outputs from (one per --log_path, in the same order)")
+	flag.StringVar(&likely, "likely_cause", "", "Comma-separated likely causes to report, guessed from output content: "+strings.Join(likelyCauseOrder, ", "))
+	flag.BoolVar(&opts.groupByLikelyCause, "group_by_likely_cause", false, "Group the report by the likely cause of each difference")
+	flag.StringVar(&opts.outputFormat, "output_format", "text", "Report format on stdout: text, json or markdown")
+	flag.StringVar(&opts.jsonReport, "json_report", "", "Also write the JSON report to this file")
+	flag.StringVar(&opts.junitReport, "junit_report", "", "Write a JUnit XML report to this file, with a failing test case per non-deterministic action")
+	flag.StringVar(&opts.junitGroupBy, "junit_group_by", junitByAction, "One JUnit test case per action or per target")
+	flag.StringVar(&opts.htmlReport, "html_report", "", "Write a self-contained HTML report to this file")
+	flag.StringVar(&opts.sarifReport, "sarif_report", "", "Write a SARIF 2.1.0 report to this file for code scanning")
+	flag.StringVar(&opts.workspace, "workspace_root", os.Getenv("BUILD_WORKSPACE_DIRECTORY"), "Workspace to resolve target labels to BUILD files in, for --sarif_report")
+	flag.StringVar(&opts.configPath, "config", "", "JSON file of suppressions for known non-determinism")
+	flag.Var(&pathRewrites, "path_rewrite", "Rewrite paths matching a regex before comparing, as regex=replacement (can be repeated)")
+	flag.StringVar(&pairBy, "pair_by", string(execlog.PairByFirstOutput), "How to pair actions across logs: "+pairingStrategyNames())
+	flag.IntVar(&opts.jobs, "jobs", runtime.NumCPU(), "Number of logs to decode and actions to compare in parallel")
+	flag.BoolVar(&opts.streaming, "streaming", false, "Sort the logs by key in temporary files and compare them in a single pass, keeping in memory only non-deterministic actions and the keys of other reported actions")
+	flag.StringVar(&opts.tempDir, "temp_dir", "", "Directory for the temporary files of --streaming (default: $TMPDIR)")
+	flag.StringVar(&normalizePaths, "normalize_paths", "", "Comma-separated path normalization presets: "+strings.Join(execlog.PresetNames(), ", ")+", or all")
 	flag.Parse()
 
-	os.Exit(run(logPaths, runner, verbose))
+	normalizer, err := newNormalizer(pathRewrites, normalizePaths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsageError)
+	}
+	opts.normalizer = normalizer
+	if opts.likelyCauses, err = parseLikelyCauses(likely); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsageError)
+	}
+	if opts.pairBy, err = execlog.ParsePairingStrategy(pairBy); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --pair_by: %v, want %s\n", err, pairingStrategyNames())
+		os.Exit(exitUsageError)
+	}
+	opts.outputTrees = outputTrees
+
+	os.Exit(runWithOptions(logPaths, opts))
 }
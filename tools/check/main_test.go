@@ -2,13 +2,18 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
+	execlog "tools/execlog/lib"
 	pb "tools/execlog/proto"
 	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 // writeLogs writes SpawnExec records to a temporary file and returns the path.
@@ -27,6 +32,62 @@ func writeLogs(t *testing.T, dir, name string, execs []*pb.SpawnExec) string {
 	return path
 }
 
+// writeJSONLog writes SpawnExec records as concatenated JSON objects, the
+// layout of --execution_log_json_file, and returns the path.
+func writeJSONLog(t *testing.T, dir, name string, execs []*pb.SpawnExec) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	var buf bytes.Buffer
+	for _, exec := range execs {
+		data, err := protojson.MarshalOptions{Multiline: true}.Marshal(exec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(data)
+		buf.WriteString("\n\n")
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// captureStdout runs f and returns what it printed to stdout.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	defer func() {
+		os.Stdout = stdout
+	}()
+	f()
+	w.Close()
+	return string(<-done)
+}
+
+// genrule returns a remotable Genrule SpawnExec writing output with the given hash.
+func genrule(output, hash string) *pb.SpawnExec {
+	return &pb.SpawnExec{
+		CommandArgs:   []string{"/bin/echo", output},
+		ListedOutputs: []string{output},
+		Remotable:     true,
+		Cacheable:     true,
+		Mnemonic:      "Genrule",
+		ActualOutputs: []*pb.File{
+			{Path: output, Digest: &pb.Digest{Hash: hash, SizeBytes: 10}},
+		},
+	}
+}
+
 func TestIdenticalLogs_Exit0(t *testing.T) {
 	dir := t.TempDir()
 	actions := []*pb.SpawnExec{
@@ -85,6 +146,44 @@ func TestDifferentActualOutputs_Remotable_Exit1(t *testing.T) {
 	}
 }
 
+func TestJSONAgainstBinaryLog(t *testing.T) {
+	dir := t.TempDir()
+	actions := []*pb.SpawnExec{
+		{
+			CommandArgs:   []string{"/bin/echo", "hello"},
+			ListedOutputs: []string{"out/a.txt"},
+			Remotable:     true,
+			Cacheable:     true,
+			Mnemonic:      "Genrule",
+			ActualOutputs: []*pb.File{
+				{Path: "out/a.txt", Digest: &pb.Digest{Hash: "abc123", SizeBytes: 10}},
+			},
+		},
+	}
+	changed := []*pb.SpawnExec{
+		{
+			CommandArgs:   []string{"/bin/echo", "hello"},
+			ListedOutputs: []string{"out/a.txt"},
+			Remotable:     true,
+			Cacheable:     true,
+			Mnemonic:      "Genrule",
+			ActualOutputs: []*pb.File{
+				{Path: "out/a.txt", Digest: &pb.Digest{Hash: "def456", SizeBytes: 10}},
+			},
+		},
+	}
+	binary := writeLogs(t, dir, "log1.bin", actions)
+	same := writeJSONLog(t, dir, "same.json", actions)
+	different := writeJSONLog(t, dir, "different.json", changed)
+
+	if code := run([]string{same, binary}, "", false); code != exitDeterministic {
+		t.Errorf("identical JSON and binary logs: got exit code %d, want %d", code, exitDeterministic)
+	}
+	if code := run([]string{binary, different}, "", false); code != exitNonDeterministic {
+		t.Errorf("differing JSON and binary logs: got exit code %d, want %d", code, exitNonDeterministic)
+	}
+}
+
 func TestDifferentOutputs_NotRemotableNotCacheable_Exit0(t *testing.T) {
 	dir := t.TempDir()
 	actions1 := []*pb.SpawnExec{
@@ -229,6 +328,23 @@ func TestWrongArgCount_Exit2(t *testing.T) {
 	}
 }
 
+func TestUnrecognizedLogFormat_Exit2(t *testing.T) {
+	dir := t.TempDir()
+	actions := []*pb.SpawnExec{
+		{CommandArgs: []string{"/bin/echo", "hello"}, ListedOutputs: []string{"out/a.txt"}},
+	}
+	log1 := writeLogs(t, dir, "log1.bin", actions)
+	garbage := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(garbage, []byte("these are not the logs you are looking for\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	code := run([]string{log1, garbage}, "", false)
+	if code != exitUsageError {
+		t.Errorf("unrecognized log format: got exit code %d, want %d", code, exitUsageError)
+	}
+}
+
 func TestVerboseDetails(t *testing.T) {
 	a := &pb.SpawnExec{
 		CommandArgs: []string{"/bin/echo", "hello"},
@@ -330,3 +446,165 @@ func TestVerboseDetails(t *testing.T) {
 		}
 	})
 }
+
+func TestNWay_OutlierDetection(t *testing.T) {
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{genrule("out/a.txt", "aaa"), genrule("out/b.txt", "bbb")})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{genrule("out/a.txt", "aaa"), genrule("out/b.txt", "bbb")})
+	log3 := writeLogs(t, dir, "log3.bin", []*pb.SpawnExec{genrule("out/a.txt", "zzz"), genrule("out/b.txt", "bbb")})
+
+	var code int
+	out := captureStdout(t, func() {
+		code = run([]string{log1, log2, log3}, "", true)
+	})
+	if code != exitNonDeterministic {
+		t.Errorf("got exit code %d, want %d", code, exitNonDeterministic)
+	}
+	for _, want := range []string{
+		"Non-deterministic actions found: 1",
+		"majority: log1, log2; outliers: log3",
+		"log3 vs log1:",
+		"hash=aaa size=10 -> hash=zzz size=10",
+		"log3: 1 action(s)",
+		"Summary: 2 paired actions compared across 3 logs, 1 non-deterministic",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestNWay_NoMajority(t *testing.T) {
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{genrule("out/a.txt", "111")})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{genrule("out/a.txt", "222")})
+	log3 := writeLogs(t, dir, "log3.bin", []*pb.SpawnExec{genrule("out/a.txt", "333")})
+
+	out := captureStdout(t, func() {
+		run([]string{log1, log2, log3}, "", false)
+	})
+	if !strings.Contains(out, "no majority: every log produced a different result") {
+		t.Errorf("expected no-majority verdict:\n%s", out)
+	}
+}
+
+func TestNWay_TieIsNoMajority(t *testing.T) {
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{genrule("out/a.txt", "aaa")})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{genrule("out/a.txt", "aaa")})
+	log3 := writeLogs(t, dir, "log3.bin", []*pb.SpawnExec{genrule("out/a.txt", "bbb")})
+	log4 := writeLogs(t, dir, "log4.bin", []*pb.SpawnExec{genrule("out/a.txt", "bbb")})
+	paths := []string{log1, log2, log3, log4}
+
+	out := captureStdout(t, func() {
+		run(paths, "", false)
+	})
+	if !strings.Contains(out, "no majority: reference: log1, log2; others: log3, log4") {
+		t.Errorf("expected a 2/2 split to have no majority:\n%s", out)
+	}
+	if strings.Contains(out, "majority: log1") {
+		t.Errorf("a tie is reported as a majority:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		runWithOptions(paths, options{outputFormat: "json"})
+	})
+	var rep jsonReport
+	if err := json.Unmarshal([]byte(out), &rep); err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Actions) != 1 {
+		t.Fatalf("got %d actions, want 1", len(rep.Actions))
+	}
+	action := rep.Actions[0]
+	if !action.NoMajority || !reflect.DeepEqual(action.Majority, []int{1, 2}) || !reflect.DeepEqual(action.Outliers, []int{3, 4}) {
+		t.Errorf("unexpected JSON verdict: %+v", action)
+	}
+}
+
+func TestNWay_MissingFromOneLog(t *testing.T) {
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{genrule("out/a.txt", "aaa"), genrule("out/b.txt", "bbb")})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{genrule("out/a.txt", "aaa"), genrule("out/b.txt", "bbb")})
+	log3 := writeLogs(t, dir, "log3.bin", []*pb.SpawnExec{genrule("out/a.txt", "aaa")})
+
+	var code int
+	out := captureStdout(t, func() {
+		code = run([]string{log1, log2, log3}, "", false)
+	})
+	if code != exitDeterministic {
+		t.Errorf("got exit code %d, want %d", code, exitDeterministic)
+	}
+	if !strings.Contains(out, "Actions missing from log3: 1\n  out/b.txt") {
+		t.Errorf("expected out/b.txt missing from log3:\n%s", out)
+	}
+}
+
+func TestNormalizePaths_PairsAcrossConfigurations(t *testing.T) {
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{genrule("bazel-out/k8-fastbuild/bin/a.txt", "aaa")})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{genrule("bazel-out/k8-opt/bin/a.txt", "aaa")})
+
+	out := captureStdout(t, func() {
+		run([]string{log1, log2}, "", false)
+	})
+	if !strings.Contains(out, "Summary: 0 paired actions compared") {
+		t.Errorf("without normalization nothing should pair:\n%s", out)
+	}
+
+	normalizer, err := newNormalizer([]string{"/home/[^/]+/ws=<workspace>"}, "config,execroot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var code int
+	out = captureStdout(t, func() {
+		code = runWithOptions([]string{log1, log2}, options{normalizer: normalizer})
+	})
+	if code != exitDeterministic {
+		t.Errorf("got exit code %d, want %d", code, exitDeterministic)
+	}
+	if !strings.Contains(out, "Summary: 1 paired actions compared, 0 non-deterministic") {
+		t.Errorf("expected the normalized actions to pair:\n%s", out)
+	}
+
+	if _, err := newNormalizer(nil, "config,bogus"); err == nil {
+		t.Error("expected an error for an unknown preset")
+	}
+}
+
+func TestPairBy(t *testing.T) {
+	dir := t.TempDir()
+	// A test action that lists no outputs, and an output named by a hash.
+	test := func(hash string) *pb.SpawnExec {
+		exec := genrule("", hash)
+		exec.ListedOutputs, exec.Mnemonic, exec.TargetLabel = nil, "TestRunner", "//pkg:test"
+		exec.ActualOutputs[0].Path = "testlogs/pkg/test/test.xml"
+		return exec
+	}
+	hashed := func(name, hash string) *pb.SpawnExec {
+		exec := genrule(name, hash)
+		exec.TargetLabel = "//pkg:gen"
+		return exec
+	}
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{test("aaa"), hashed("out/gen-1a2b.txt", "bbb")})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{test("ccc"), hashed("out/gen-9f8e.txt", "bbb")})
+
+	out := captureStdout(t, func() {
+		run([]string{log1, log2}, "", false)
+	})
+	if !strings.Contains(out, "Summary: 0 paired actions compared") {
+		t.Errorf("expected nothing to pair by first output:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		runWithOptions([]string{log1, log2}, options{pairBy: execlog.PairByTarget})
+	})
+	for _, want := range []string{
+		"  //pkg:test TestRunner #1 [TestRunner] (//pkg:test)\n",
+		"Summary: 2 paired actions compared, 2 non-deterministic",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Limits that keep the markdown report within the comment size limits of
+// code review sites (65536 characters on GitHub).
+const (
+	// maxMarkdownOffenders caps the rows of the top offenders table.
+	maxMarkdownOffenders = 10
+	// maxMarkdownActionLines caps the diff lines shown per action.
+	maxMarkdownActionLines = 40
+	// maxMarkdownSize is the size after which action details are dropped.
+	maxMarkdownSize = 60000
+)
+
+// offender counts the non-deterministic actions of one mnemonic and target.
+type offender struct {
+	mnemonic, targetLabel string
+	actions, origins      int
+}
+
+// topOffenders groups actions by mnemonic and target label, most actions
+// first.
+func topOffenders(results []diffResult) []offender {
+	index := make(map[[2]string]int)
+	var offenders []offender
+	for _, d := range results {
+		k := [2]string{d.mnemonic, d.targetLabel}
+		i, ok := index[k]
+		if !ok {
+			i = len(offenders)
+			index[k] = i
+			offenders = append(offenders, offender{mnemonic: d.mnemonic, targetLabel: d.targetLabel})
+		}
+		offenders[i].actions++
+		if category(d) == "origin" {
+			offenders[i].origins++
+		}
+	}
+	sort.SliceStable(offenders, func(i, j int) bool {
+		if offenders[i].actions != offenders[j].actions {
+			return offenders[i].actions > offenders[j].actions
+		}
+		return offenders[i].origins > offenders[j].origins
+	})
+	return offenders
+}
+
+// markdownCell escapes s for a markdown table cell.
+func markdownCell(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(html.EscapeString(s), "|", `\|`)
+}
+
+// codeFence returns a fence longer than any run of backticks in lines.
+func codeFence(lines []string) string {
+	longest := 0
+	for _, line := range lines {
+		run := 0
+		for _, c := range line {
+			if c == '`' {
+				run++
+				longest = max(longest, run)
+			} else {
+				run = 0
+			}
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// writeMarkdownReport writes a compact summary of r for pull request
+// comments: a verdict, the top offenders and collapsible per-action diffs.
+func writeMarkdownReport(w io.Writer, r *checkResult) error {
+	var b strings.Builder
+	s := newReportSummary(r)
+	if s.NonDeterministic == 0 {
+		fmt.Fprintf(&b, "## :white_check_mark: Determinism check passed\n\n")
+	} else {
+		fmt.Fprintf(&b, "## :x: Determinism check found %d non-deterministic action(s)\n\n", s.NonDeterministic)
+	}
+	fmt.Fprintf(&b, "Compared %d paired actions across %d logs: %d non-deterministic (%s)",
+		s.PairedActions, len(r.logs), s.NonDeterministic, categoryCounts(s.Origins, s.Propagated, s.OrderingOnly, s.OrderingOnly > 0))
+	fmt.Fprintf(&b, ", %d suppressed, %d skipped as neither remotable nor cacheable.\n", s.Suppressed, s.Skipped)
+
+	if len(r.reported) > 0 {
+		offenders := topOffenders(r.reported)
+		fmt.Fprintf(&b, "\n### Top offenders\n\n| Mnemonic | Target | Actions | Origins |\n|---|---|---:|---:|\n")
+		for i, o := range offenders {
+			if i == maxMarkdownOffenders {
+				fmt.Fprintf(&b, "\n…and %d more mnemonic/target combination(s).\n", len(offenders)-maxMarkdownOffenders)
+				break
+			}
+			fmt.Fprintf(&b, "| %s | %s | %d | %d |\n", markdownCell(o.mnemonic), markdownCell(o.targetLabel), o.actions, o.origins)
+		}
+
+		// Origins first: they are where fixes are needed.
+		results := append([]diffResult{}, r.reported...)
+		order := map[string]int{"origin": 0, "propagated": 1, "ordering_only": 2}
+		sort.SliceStable(results, func(i, j int) bool {
+			return order[category(results[i])] < order[category(results[j])]
+		})
+		fmt.Fprintf(&b, "\n### Non-deterministic actions\n\n")
+		nWay := len(r.logs) > 2
+		for i, d := range results {
+			var details strings.Builder
+			summary := fmt.Sprintf("<code>%s</code> [%s]", html.EscapeString(d.key), html.EscapeString(d.mnemonic))
+			if d.targetLabel != "" {
+				summary += " " + html.EscapeString(d.targetLabel)
+			}
+			summary += fmt.Sprintf(": %s, differs in %s", strings.ReplaceAll(category(d), "_", "-"), strings.Join(d.sections, ", "))
+			lines := formatDiffResult(d, true, r.store, nWay)[1:]
+			if len(lines) > maxMarkdownActionLines {
+				lines = append(lines[:maxMarkdownActionLines], fmt.Sprintf("... (%d more lines)", len(lines)-maxMarkdownActionLines))
+			}
+			fence := codeFence(lines)
+			fmt.Fprintf(&details, "<details>\n<summary>%s</summary>\n\n%s\n%s\n%s\n\n</details>\n\n", summary, fence, strings.Join(lines, "\n"), fence)
+			if b.Len()+details.Len() > maxMarkdownSize {
+				fmt.Fprintf(&b, "…and %d more action(s) not shown to keep this comment short. See the full report for details.\n", len(results)-i)
+				break
+			}
+			b.WriteString(details.String())
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
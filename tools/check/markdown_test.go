@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestMarkdownReport(t *testing.T) {
+	dir := t.TempDir()
+	var log1Execs, log2Execs []*pb.SpawnExec
+	for i := 0; i < 3; i++ {
+		a := genrule(fmt.Sprintf("out/gen%d.txt", i), "aaa")
+		a.TargetLabel = "//pkg:gen"
+		b := genrule(fmt.Sprintf("out/gen%d.txt", i), "bbb")
+		b.TargetLabel = "//pkg:gen"
+		log1Execs = append(log1Execs, a)
+		log2Execs = append(log2Execs, b)
+	}
+	cc := genrule("out/lib.o", "ccc")
+	cc.Mnemonic, cc.TargetLabel = "CppCompile", "//pkg:lib"
+	cc2 := genrule("out/lib.o", "ddd")
+	cc2.Mnemonic, cc2.TargetLabel = "CppCompile", "//pkg:lib"
+	log1 := writeLogs(t, dir, "log1.bin", append(log1Execs, cc))
+	log2 := writeLogs(t, dir, "log2.bin", append(log2Execs, cc2))
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runWithOptions([]string{log1, log2}, options{outputFormat: "markdown"})
+	})
+	if code != exitNonDeterministic {
+		t.Errorf("got exit code %d, want %d", code, exitNonDeterministic)
+	}
+	for _, want := range []string{
+		"## :x: Determinism check found 4 non-deterministic action(s)\n",
+		"Compared 4 paired actions across 2 logs: 4 non-deterministic (4 origin, 0 propagated)",
+		"| Mnemonic | Target | Actions | Origins |\n|---|---|---:|---:|\n| Genrule | //pkg:gen | 3 | 3 |\n| CppCompile | //pkg:lib | 1 | 1 |\n",
+		"<details>\n<summary><code>out/gen0.txt</code> [Genrule] //pkg:gen: origin, differs in actual_outputs</summary>\n\n```\n",
+		"      changed: out/gen0.txt (hash=aaa size=10 -> hash=bbb size=10)\n```\n\n</details>\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Summary:") {
+		t.Errorf("expected no text report:\n%s", out)
+	}
+
+	same := writeLogs(t, dir, "same.bin", log1Execs)
+	out = captureStdout(t, func() {
+		runWithOptions([]string{same, same}, options{outputFormat: "markdown"})
+	})
+	if !strings.HasPrefix(out, "## :white_check_mark: Determinism check passed\n") {
+		t.Errorf("expected a passing verdict:\n%s", out)
+	}
+}
+
+func TestMarkdownReport_Truncates(t *testing.T) {
+	var results []diffResult
+	for i := 0; i < 500; i++ {
+		a := genrule(fmt.Sprintf("out/%03d.txt", i), "aaa")
+		a.CommandArgs = append(a.CommandArgs, strings.Repeat("x", 200))
+		b := genrule(fmt.Sprintf("out/%03d.txt", i), "bbb")
+		results = append(results, diffResult{
+			key:          a.ActualOutputs[0].Path,
+			mnemonic:     fmt.Sprintf("Mnemonic%d", i),
+			sections:     []string{"command_args", "actual_outputs"},
+			a:            a,
+			b:            b,
+			majority:     []int{0},
+			outliers:     []int{1},
+			outlierExecs: []*pb.SpawnExec{b},
+			cause:        causeOrigin,
+		})
+	}
+	var out strings.Builder
+	if err := writeMarkdownReport(&out, &checkResult{logs: []string{"a", "b"}, reported: results}); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() > maxMarkdownSize+2000 {
+		t.Errorf("report is %d bytes, want at most about %d", out.Len(), maxMarkdownSize)
+	}
+	if !strings.Contains(out.String(), "more mnemonic/target combination(s)") {
+		t.Error("expected the offenders table to be truncated")
+	}
+	if !strings.Contains(out.String(), "more action(s) not shown") {
+		t.Error("expected the action details to be truncated")
+	}
+}
+
+func TestCodeFence(t *testing.T) {
+	if got := codeFence([]string{"plain"}); got != "```" {
+		t.Errorf("got %q", got)
+	}
+	if got := codeFence([]string{"has ```` fence"}); got != "`````" {
+		t.Errorf("got %q", got)
+	}
+}
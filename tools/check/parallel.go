@@ -0,0 +1,93 @@
+package main
+
+import (
+	"sync"
+
+	pb "tools/execlog/proto"
+)
+
+// workers returns the number of goroutines to use for jobs, at least one.
+func workers(jobs int) int {
+	if jobs < 1 {
+		return 1
+	}
+	return jobs
+}
+
+// forEach calls f for every index in [0, n) on up to jobs goroutines and
+// returns when all calls have returned.
+func forEach(jobs, n int, f func(i int)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers(jobs), n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				f(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// compareTask is one key to compare, with the channel to send its outcome to.
+type compareTask struct {
+	key    string
+	execs  []*pb.SpawnExec
+	result chan comparison
+}
+
+// comparePool compares actions on a pool of workers and records the
+// outcomes in the order the actions were added, whatever order the workers
+// finish in. At most a few actions per worker are in flight, so memory does
+// not grow with the number of actions.
+type comparePool struct {
+	tasks   chan compareTask
+	results chan chan comparison
+	done    chan struct{}
+}
+
+// newComparePool starts jobs workers calling compare and a goroutine
+// calling record with their outcomes, one at a time.
+func newComparePool(jobs int, compare func(key string, execs []*pb.SpawnExec) comparison, record func(comparison)) *comparePool {
+	n := workers(jobs)
+	p := &comparePool{
+		tasks:   make(chan compareTask),
+		results: make(chan chan comparison, 2*n),
+		done:    make(chan struct{}),
+	}
+	for w := 0; w < n; w++ {
+		go func() {
+			for t := range p.tasks {
+				t.result <- compare(t.key, t.execs)
+			}
+		}()
+	}
+	go func() {
+		for result := range p.results {
+			record(<-result)
+		}
+		close(p.done)
+	}()
+	return p
+}
+
+// add queues the actions of key for comparison, blocking while too many
+// are in flight.
+func (p *comparePool) add(key string, execs []*pb.SpawnExec) {
+	result := make(chan comparison, 1)
+	p.results <- result
+	p.tasks <- compareTask{key, execs, result}
+}
+
+// wait stops the workers once all queued actions are recorded.
+func (p *comparePool) wait() {
+	close(p.tasks)
+	close(p.results)
+	<-p.done
+}
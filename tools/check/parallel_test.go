@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	pb "tools/execlog/proto"
+)
+
+func TestForEach(t *testing.T) {
+	for _, jobs := range []int{0, 1, 3, 20} {
+		done := make([]int, 10)
+		forEach(jobs, len(done), func(i int) { done[i]++ })
+		for i, n := range done {
+			if n != 1 {
+				t.Errorf("jobs=%d: index %d called %d times", jobs, i, n)
+			}
+		}
+	}
+}
+
+func TestComparePool_RecordsInOrder(t *testing.T) {
+	var got []string
+	pool := newComparePool(4, func(key string, execs []*pb.SpawnExec) comparison {
+		// Earlier keys finish last.
+		time.Sleep(time.Duration(len(execs)) * time.Millisecond)
+		return comparison{key: key}
+	}, func(c comparison) {
+		got = append(got, c.key)
+	})
+	var want []string
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("out/%02d", i)
+		want = append(want, key)
+		pool.add(key, make([]*pb.SpawnExec, 20-i))
+	}
+	pool.wait()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("recorded %q, want %q", got, want)
+	}
+}
+
+func TestJobs_MatchesSerial(t *testing.T) {
+	dir := t.TempDir()
+	var execs1, execs2 []*pb.SpawnExec
+	for i := 0; i < 50; i++ {
+		output := fmt.Sprintf("out/%02d.txt", i)
+		execs1 = append(execs1, genrule(output, "aaa"))
+		hash := "aaa"
+		if i%3 == 0 {
+			hash = "bbb"
+		}
+		execs2 = append(execs2, genrule(output, hash))
+	}
+	execs1 = append(execs1, genrule("out/only1.txt", "ccc"))
+	paths := []string{
+		writeLogs(t, dir, "log1.bin", execs1),
+		writeLogs(t, dir, "log2.bin", execs2),
+	}
+
+	for _, streaming := range []bool{false, true} {
+		want := captureStdout(t, func() {
+			runWithOptions(paths, options{verbose: true, streaming: streaming, jobs: 1})
+		})
+		got := captureStdout(t, func() {
+			runWithOptions(paths, options{verbose: true, streaming: streaming, jobs: 8})
+		})
+		if got != want {
+			t.Errorf("streaming=%v: output with 8 jobs differs:\n%s\nwith 1 job:\n%s", streaming, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+
+	pb "tools/execlog/proto"
+)
+
+// paramsSection is reported when the params files passed to an action as
+// @path arguments differ. Its details diff the expanded command lines.
+const paramsSection = "command_args (params)"
+
+// paramsFiles returns the inputs passed as params files on exec's command
+// line, in order, and false if an @path argument names no input.
+func paramsFiles(exec *pb.SpawnExec) ([]*pb.File, bool) {
+	inputs := make(map[string]*pb.File)
+	for _, f := range exec.Inputs {
+		inputs[f.Path] = f
+	}
+	var files []*pb.File
+	for _, arg := range exec.CommandArgs[min(1, len(exec.CommandArgs)):] {
+		path, ok := strings.CutPrefix(arg, "@")
+		if !ok || path == "" {
+			continue
+		}
+		f := inputs[path]
+		if f == nil {
+			return nil, false
+		}
+		files = append(files, f)
+	}
+	return files, true
+}
+
+// paramsDiffer reports whether a, from log aLog, and b, from log bLog,
+// pass params files with different content that store has, so that their
+// expanded command lines can be diffed.
+func paramsDiffer(store blobStore, aLog int, a *pb.SpawnExec, bLog int, b *pb.SpawnExec) bool {
+	filesA, okA := paramsFiles(a)
+	filesB, okB := paramsFiles(b)
+	if !okA || !okB || len(filesA)+len(filesB) == 0 {
+		return false
+	}
+	same := len(filesA) == len(filesB)
+	for i := 0; same && i < len(filesA); i++ {
+		same = filesA[i].Digest.GetHash() == filesB[i].Digest.GetHash()
+	}
+	if same {
+		return false
+	}
+	_, okA = expandParams(store, aLog, a)
+	_, okB = expandParams(store, bLog, b)
+	return okA && okB
+}
+
+// parseParams splits the content of a params file into arguments, one per
+// line. Lines in Bazel's shell-quoted format, 'arg' with '\'' for quotes,
+// are unquoted.
+func parseParams(data []byte) []string {
+	lines := splitLines(data)
+	args := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if len(line) >= 2 && line[0] == '\'' && line[len(line)-1] == '\'' {
+			line = strings.ReplaceAll(line[1:len(line)-1], `'\''`, "'")
+		}
+		args = append(args, line)
+	}
+	return args
+}
+
+// expandParams returns exec's command line with every @path argument
+// replaced by the arguments in the params file, read from store for the
+// build that wrote log. It returns false if a params file is not available
+// or not text.
+func expandParams(store blobStore, log int, exec *pb.SpawnExec) ([]string, bool) {
+	files, ok := paramsFiles(exec)
+	if !ok {
+		return nil, false
+	}
+	var args []string
+	for i, arg := range exec.CommandArgs {
+		if i == 0 || len(arg) < 2 || arg[0] != '@' {
+			args = append(args, arg)
+			continue
+		}
+		data, err := store.read(log, files[0])
+		if err != nil || !isText(data) {
+			return nil, false
+		}
+		files = files[1:]
+		args = append(args, parseParams(data)...)
+	}
+	return args, true
+}
+
+// paramsChanges aligns the expanded command lines of a, from log aLog, and
+// b, from log bLog. It returns nil if a params file is not in store.
+func paramsChanges(store blobStore, aLog int, a *pb.SpawnExec, bLog int, b *pb.SpawnExec) []change {
+	argsA, okA := expandParams(store, aLog, a)
+	argsB, okB := expandParams(store, bLog, b)
+	if !okA || !okB {
+		return nil
+	}
+	return argChanges(argsA, argsB)
+}
+
+// paramsDetails returns detail lines describing how the expanded command
+// lines of a and b differ, or a note that the params files are missing.
+func paramsDetails(store blobStore, aLog int, a *pb.SpawnExec, bLog int, b *pb.SpawnExec) []string {
+	argsA, okA := expandParams(store, aLog, a)
+	argsB, okB := expandParams(store, bLog, b)
+	if !okA || !okB {
+		return []string{"  (params files not in " + store.name() + ")"}
+	}
+	return argDetails(argsA, argsB)
+}
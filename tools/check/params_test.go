@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestParseParams(t *testing.T) {
+	got := parseParams([]byte("-o\nout with space.o\n'-DNAME=it'\\''s'\n'--quoted'\n"))
+	want := []string{"-o", "out with space.o", "-DNAME=it's", "--quoted"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParams_DiffsExpandedCommandLine(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "cache")
+	link := func(output, params string) *pb.SpawnExec {
+		exec := genrule(output, "")
+		exec.Mnemonic = "CppLink"
+		exec.CommandArgs = []string{"/usr/bin/gcc", "@bazel-out/bin/app-2.params"}
+		exec.Inputs = []*pb.File{{Path: "bazel-out/bin/app-2.params", Digest: writeBlob(t, cache, params)}}
+		exec.ActualOutputs[0].Digest = writeBlob(t, cache, output+params)
+		return exec
+	}
+	a := link("bazel-out/bin/app", "-o\nbazel-out/bin/app\n-Wl,-S\na.o\nb.o\n")
+	b := link("bazel-out/bin/app", "-o\nbazel-out/bin/app\n-Wl,-S\nb.o\na.o\n-Wl,--build-id=0x1234\n")
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{a})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{b})
+
+	out := captureStdout(t, func() {
+		runWithOptions([]string{log1, log2}, options{diskCache: cache, verbose: true})
+	})
+	for _, want := range []string{
+		"    differs in: command_args (params), inputs, actual_outputs",
+		"    command_args (params):\n" +
+			"        [2] \"bazel-out/bin/app\"\n" +
+			"        [3] \"-Wl,-S\"\n" +
+			"        removed [4]: \"a.o\"\n" +
+			"        [5] \"b.o\"\n" +
+			"        added [5]: \"a.o\"\n" +
+			"        added [6]: \"-Wl,--build-id=0x1234\"\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	out = captureStdout(t, func() {
+		runWithOptions([]string{log1, log2}, options{diskCache: cache, outputFormat: "json"})
+	})
+	if !strings.Contains(out, `"name": "command_args (params)"`) || !strings.Contains(out, `"flag": "-Wl,--build-id"`) {
+		t.Errorf("expected the params changes in the JSON report:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		runWithOptions([]string{log1, log2}, options{verbose: true})
+	})
+	if strings.Contains(out, paramsSection) {
+		t.Errorf("expected no params section without a disk cache:\n%s", out)
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	pb "tools/execlog/proto"
+)
+
+// reportSchemaVersion is the version of the JSON report, documented in
+// report.schema.json. It is increased whenever a field is removed or changes
+// meaning; new fields may be added without a new version.
+const reportSchemaVersion = 1
+
+// pairedAction identifies an action found in at least two logs.
+type pairedAction struct {
+	key         string
+	mnemonic    string
+	targetLabel string
+}
+
+func newPairedAction(key string, exec *pb.SpawnExec) pairedAction {
+	mnemonic := exec.Mnemonic
+	if mnemonic == "" {
+		mnemonic = "(unknown)"
+	}
+	return pairedAction{key: key, mnemonic: mnemonic, targetLabel: exec.TargetLabel}
+}
+
+// checkResult holds everything a check run found, for the reports.
+type checkResult struct {
+	logs   []string
+	paired int
+	// passed are the paired actions that did not differ, and skipped those
+	// that differ but are neither remotable nor cacheable.
+	passed  []pairedAction
+	skipped []pairedAction
+	// reported are the non-deterministic actions that were not suppressed.
+	reported   []diffResult
+	suppressed []diffResult
+	expired    []*suppression
+	// expiredMatches counts the actions each expired suppression would
+	// have suppressed.
+	expiredMatches map[*suppression]int
+	uniqueTo       [][]string
+	missingFrom    [][]string
+	duplicates     []duplicateKey
+	// fuzzy are the unique actions paired by similar keys.
+	fuzzy []fuzzyPair
+	// store, if set, has the content of differing outputs.
+	store blobStore
+}
+
+// category names the report group of d: "origin", "propagated" or
+// "ordering_only".
+func category(d diffResult) string {
+	switch {
+	case d.orderingOnly:
+		return "ordering_only"
+	case d.cause == causePropagated:
+		return "propagated"
+	}
+	return "origin"
+}
+
+// jsonReport is the --output_format=json report. See report.schema.json.
+type jsonReport struct {
+	SchemaVersion       int                `json:"schema_version"`
+	Logs                []string           `json:"logs"`
+	Summary             reportSummary      `json:"summary"`
+	Actions             []reportAction     `json:"non_deterministic_actions"`
+	Suppressed          []reportAction     `json:"suppressed_actions"`
+	ExpiredSuppressions []reportExpired    `json:"expired_suppressions"`
+	UniqueActions       []reportLogActions `json:"unique_actions"`
+	MissingActions      []reportLogActions `json:"missing_actions"`
+	DuplicateKeys       []reportDuplicate  `json:"duplicate_keys"`
+	ProbablyPaired      []reportFuzzyPair  `json:"probably_paired"`
+}
+
+type reportSummary struct {
+	PairedActions    int `json:"paired_actions"`
+	UniqueActions    int `json:"unique_actions"`
+	NonDeterministic int `json:"non_deterministic"`
+	Origins          int `json:"origins"`
+	Propagated       int `json:"propagated"`
+	OrderingOnly     int `json:"ordering_only"`
+	Suppressed       int `json:"suppressed"`
+	Skipped          int `json:"skipped"`
+}
+
+type reportAction struct {
+	Key          string             `json:"key"`
+	Mnemonic     string             `json:"mnemonic"`
+	TargetLabel  string             `json:"target_label,omitempty"`
+	Category     string             `json:"category"`
+	Upstream     []string           `json:"upstream,omitempty"`
+	Sections     []string           `json:"sections"`
+	LikelyCauses []string           `json:"likely_causes,omitempty"`
+	Majority     []int              `json:"majority"`
+	Outliers     []int              `json:"outliers"`
+	NoMajority   bool               `json:"no_majority,omitempty"`
+	Comparisons  []reportComparison `json:"comparisons"`
+	Suppression  *reportSuppression `json:"suppression,omitempty"`
+}
+
+// reportComparison lists the changes from the reference log, the first of
+// the majority, to one outlier log.
+type reportComparison struct {
+	Reference int             `json:"reference"`
+	Log       int             `json:"log"`
+	Sections  []reportSection `json:"sections"`
+}
+
+type reportSection struct {
+	Name    string   `json:"name"`
+	Changes []change `json:"changes"`
+	// ContentDiff is the content diff of the differing outputs, as printed
+	// in the text report, when a --disk_cache or --output_tree is given.
+	ContentDiff []string `json:"content_diff,omitempty"`
+}
+
+type reportSuppression struct {
+	Owner   string `json:"owner"`
+	Reason  string `json:"reason"`
+	Expires string `json:"expires,omitempty"`
+}
+
+type reportExpired struct {
+	Suppression         string `json:"suppression"`
+	Owner               string `json:"owner"`
+	Reason              string `json:"reason"`
+	Expires             string `json:"expires"`
+	WouldHaveSuppressed int    `json:"would_have_suppressed"`
+}
+
+type reportDuplicate struct {
+	Key string `json:"key"`
+	// Counts holds the number of actions with the key in each log.
+	Counts   []int  `json:"counts"`
+	PairedBy string `json:"paired_by"`
+}
+
+// reportFuzzyPair is a pair of unique actions that are probably the same
+// action. Its sections list the changes from the first to the second.
+type reportFuzzyPair struct {
+	Logs        []int           `json:"logs"`
+	Keys        []string        `json:"keys"`
+	Mnemonic    string          `json:"mnemonic"`
+	TargetLabel string          `json:"target_label,omitempty"`
+	Sections    []reportSection `json:"sections"`
+}
+
+type reportLogActions struct {
+	Log  int      `json:"log"`
+	Keys []string `json:"keys"`
+}
+
+// logNumbers converts log indexes to the 1-based numbers used in reports.
+func logNumbers(indexes []int) []int {
+	numbers := make([]int, len(indexes))
+	for i, idx := range indexes {
+		numbers[i] = idx + 1
+	}
+	return numbers
+}
+
+func newReportAction(d diffResult, store blobStore) reportAction {
+	action := reportAction{
+		Key:          d.key,
+		Mnemonic:     d.mnemonic,
+		TargetLabel:  d.targetLabel,
+		Category:     category(d),
+		Upstream:     d.upstream,
+		Sections:     d.sections,
+		LikelyCauses: d.likelyCauses,
+		Majority:     logNumbers(d.majority),
+		Outliers:     logNumbers(d.outliers),
+		Comparisons:  []reportComparison{},
+	}
+	action.NoMajority = d.noMajority
+	for i, outlier := range d.outlierExecs {
+		comparison := reportComparison{Reference: d.majority[0] + 1, Log: d.outliers[i] + 1, Sections: []reportSection{}}
+		for _, section := range d.sections {
+			s := reportSection{Name: section, Changes: sectionChanges(section, d.a, outlier)}
+			if section == paramsSection {
+				s.Changes = paramsChanges(store, d.majority[0], d.a, d.outliers[i], outlier)
+			}
+			if s.Changes == nil {
+				s.Changes = []change{}
+			}
+			if section == "actual_outputs" && store != nil {
+				s.ContentDiff = outputContentDiffs(store, d.majority[0], d.a, d.outliers[i], outlier)
+			}
+			comparison.Sections = append(comparison.Sections, s)
+		}
+		action.Comparisons = append(action.Comparisons, comparison)
+	}
+	if sup := d.suppressedBy; sup != nil {
+		action.Suppression = &reportSuppression{Owner: sup.Owner, Reason: sup.Reason, Expires: sup.Expires}
+	}
+	return action
+}
+
+// newReportSummary counts the actions of r by outcome, without building
+// the per-action details of the reports.
+func newReportSummary(r *checkResult) reportSummary {
+	s := reportSummary{
+		PairedActions:    r.paired,
+		NonDeterministic: len(r.reported),
+		Suppressed:       len(r.suppressed),
+		Skipped:          len(r.skipped),
+	}
+	for _, d := range r.reported {
+		switch category(d) {
+		case "origin":
+			s.Origins++
+		case "propagated":
+			s.Propagated++
+		default:
+			s.OrderingOnly++
+		}
+	}
+	for _, keys := range r.uniqueTo {
+		s.UniqueActions += len(keys)
+	}
+	return s
+}
+
+// newJSONReport builds the JSON report of r.
+func newJSONReport(r *checkResult) *jsonReport {
+	rep := &jsonReport{
+		SchemaVersion:       reportSchemaVersion,
+		Logs:                r.logs,
+		Actions:             []reportAction{},
+		Suppressed:          []reportAction{},
+		ExpiredSuppressions: []reportExpired{},
+		UniqueActions:       []reportLogActions{},
+		MissingActions:      []reportLogActions{},
+		DuplicateKeys:       []reportDuplicate{},
+		ProbablyPaired:      []reportFuzzyPair{},
+	}
+	rep.Summary = newReportSummary(r)
+	for _, d := range r.reported {
+		rep.Actions = append(rep.Actions, newReportAction(d, r.store))
+	}
+	for _, d := range r.suppressed {
+		rep.Suppressed = append(rep.Suppressed, newReportAction(d, r.store))
+	}
+	for _, sup := range r.expired {
+		rep.ExpiredSuppressions = append(rep.ExpiredSuppressions, reportExpired{
+			Suppression:         sup.describe(),
+			Owner:               sup.Owner,
+			Reason:              sup.Reason,
+			Expires:             sup.Expires,
+			WouldHaveSuppressed: r.expiredMatches[sup],
+		})
+	}
+	for i, keys := range r.uniqueTo {
+		if len(keys) > 0 {
+			rep.UniqueActions = append(rep.UniqueActions, reportLogActions{Log: i + 1, Keys: keys})
+		}
+	}
+	for i, keys := range r.missingFrom {
+		if len(keys) > 0 && len(r.logs) > 2 {
+			rep.MissingActions = append(rep.MissingActions, reportLogActions{Log: i + 1, Keys: keys})
+		}
+	}
+	for _, p := range r.fuzzy {
+		pair := reportFuzzyPair{
+			Logs:        []int{p.logA + 1, p.logB + 1},
+			Keys:        []string{p.keyA, p.keyB},
+			Mnemonic:    p.a.Mnemonic,
+			TargetLabel: p.a.TargetLabel,
+			Sections:    []reportSection{},
+		}
+		for _, section := range p.sections {
+			s := reportSection{Name: section, Changes: sectionChanges(section, p.a, p.b)}
+			if s.Changes == nil {
+				s.Changes = []change{}
+			}
+			pair.Sections = append(pair.Sections, s)
+		}
+		rep.ProbablyPaired = append(rep.ProbablyPaired, pair)
+	}
+	for _, dup := range r.duplicates {
+		rep.DuplicateKeys = append(rep.DuplicateKeys, reportDuplicate{Key: dup.key, Counts: dup.counts, PairedBy: dup.pairedBy})
+	}
+	return rep
+}
+
+// writeJSONReport writes the JSON report of r to w.
+func writeJSONReport(w io.Writer, r *checkResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(newJSONReport(r))
+}
+
+// writeReportFile writes a report to path with write.
+func writeReportFile(path string, r *checkResult, write func(io.Writer, *checkResult) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestJSONReport(t *testing.T) {
+	dir := t.TempDir()
+	a := genrule("out/a.txt", "aaa")
+	a.TargetLabel = "//pkg:a"
+	a.EnvironmentVariables = []*pb.EnvironmentVariable{{Name: "TZ", Value: ""}}
+	b := genrule("out/a.txt", "bbb")
+	b.TargetLabel = "//pkg:a"
+	b.EnvironmentVariables = []*pb.EnvironmentVariable{{Name: "TZ", Value: "UTC"}}
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{a, genrule("out/only1.txt", "ccc")})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{b})
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runWithOptions([]string{log1, log2}, options{outputFormat: "json"})
+	})
+	if code != exitNonDeterministic {
+		t.Errorf("got exit code %d, want %d", code, exitNonDeterministic)
+	}
+	var rep jsonReport
+	if err := json.Unmarshal([]byte(out), &rep); err != nil {
+		t.Fatalf("stdout is not a JSON report: %v\n%s", err, out)
+	}
+	if rep.SchemaVersion != reportSchemaVersion || len(rep.Logs) != 2 {
+		t.Errorf("got schema version %d and logs %v", rep.SchemaVersion, rep.Logs)
+	}
+	want := reportSummary{PairedActions: 1, UniqueActions: 1, NonDeterministic: 1, Origins: 1}
+	if rep.Summary != want {
+		t.Errorf("got summary %+v, want %+v", rep.Summary, want)
+	}
+	if len(rep.UniqueActions) != 1 || rep.UniqueActions[0].Log != 1 || rep.UniqueActions[0].Keys[0] != "out/only1.txt" {
+		t.Errorf("got unique actions %+v", rep.UniqueActions)
+	}
+
+	if len(rep.Actions) != 1 {
+		t.Fatalf("got %d actions, want 1", len(rep.Actions))
+	}
+	action := rep.Actions[0]
+	if action.Key != "out/a.txt" || action.Mnemonic != "Genrule" || action.TargetLabel != "//pkg:a" || action.Category != "origin" {
+		t.Errorf("got action %+v", action)
+	}
+	if len(action.Comparisons) != 1 || action.Comparisons[0].Reference != 1 || action.Comparisons[0].Log != 2 {
+		t.Fatalf("got comparisons %+v", action.Comparisons)
+	}
+	sections := action.Comparisons[0].Sections
+	if len(sections) != 2 || sections[0].Name != "environment_variables" || sections[1].Name != "actual_outputs" {
+		t.Fatalf("got sections %+v", sections)
+	}
+	env := sections[0].Changes
+	if len(env) != 1 || env[0].Kind != changeChanged || env[0].Name != "TZ" || env[0].Old == nil || *env[0].Old != "" || *env[0].New != "UTC" {
+		t.Errorf("got environment changes %+v", env)
+	}
+	outputs := sections[1].Changes
+	if len(outputs) != 1 || outputs[0].OldDigest.Hash != "aaa" || outputs[0].NewDigest.Hash != "bbb" {
+		t.Errorf("got output changes %+v", outputs)
+	}
+}
+
+func TestJSONReport_File(t *testing.T) {
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{genrule("out/a.txt", "aaa")})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{genrule("out/a.txt", "aaa")})
+	path := filepath.Join(dir, "report.json")
+
+	out := captureStdout(t, func() {
+		runWithOptions([]string{log1, log2}, options{jsonReport: path})
+	})
+	if !strings.Contains(out, "Summary: 1 paired actions compared") {
+		t.Errorf("expected the text report on stdout, got:\n%s", out)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rep map[string]any
+	if err := json.Unmarshal(data, &rep); err != nil {
+		t.Fatal(err)
+	}
+	if actions, ok := rep["non_deterministic_actions"].([]any); !ok || len(actions) != 0 {
+		t.Errorf("expected an empty list of actions, got %v", rep["non_deterministic_actions"])
+	}
+
+	if code := runWithOptions([]string{log1, log2}, options{outputFormat: "xml"}); code != exitUsageError {
+		t.Errorf("unknown format: got exit code %d, want %d", code, exitUsageError)
+	}
+}
+
+// TestReportSchema_DocumentsEveryField keeps report.schema.json in sync
+// with the report types.
+func TestReportSchema_DocumentsEveryField(t *testing.T) {
+	data, err := os.ReadFile("report.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("report.schema.json: %v", err)
+	}
+	if v := schema["properties"].(map[string]any)["schema_version"].(map[string]any)["const"]; v != float64(reportSchemaVersion) {
+		t.Errorf("schema documents version %v, want %d", v, reportSchemaVersion)
+	}
+
+	seen := make(map[reflect.Type]bool)
+	var check func(reflect.Type)
+	check = func(typ reflect.Type) {
+		for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || seen[typ] {
+			return
+		}
+		seen[typ] = true
+		for i := 0; i < typ.NumField(); i++ {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if !strings.Contains(string(data), `"`+name+`": {`) {
+				t.Errorf("field %s.%s (%q) is not documented in report.schema.json", typ.Name(), typ.Field(i).Name, name)
+			}
+			check(typ.Field(i).Type)
+		}
+	}
+	check(reflect.TypeOf(jsonReport{}))
+}
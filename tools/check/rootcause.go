@@ -0,0 +1,103 @@
+package main
+
+import (
+	"sort"
+
+	pb "tools/execlog/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// Root-cause labels for non-deterministic actions.
+const (
+	// causeOrigin marks an action whose differences are not explained by
+	// upstream outputs: its command, environment, platform or source inputs
+	// differ, or its outputs differ despite identical inputs.
+	causeOrigin = "origin"
+	// causePropagated marks an action that differs only because outputs of
+	// upstream actions it consumes changed.
+	causePropagated = "propagated"
+)
+
+// changedPaths returns the sorted paths whose digest differs between two
+// file lists, including paths present in only one of them.
+func changedPaths(aFiles, bFiles []*pb.File) []string {
+	aMap := make(map[string]*pb.Digest)
+	for _, f := range aFiles {
+		aMap[f.Path] = f.Digest
+	}
+	bMap := make(map[string]*pb.Digest)
+	for _, f := range bFiles {
+		bMap[f.Path] = f.Digest
+	}
+
+	var paths []string
+	for path, da := range aMap {
+		db, ok := bMap[path]
+		if !ok || !proto.Equal(da, db) {
+			paths = append(paths, path)
+		}
+	}
+	for path := range bMap {
+		if _, ok := aMap[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// producerGraph maps every output path whose content changed between the
+// compared logs to the key of the action that produced it. Together with
+// each action's inputs it forms the producer/consumer graph used to tell
+// originating non-determinism from its downstream cascade.
+type producerGraph map[string]string
+
+// addChangedOutputs records the outputs of the action identified by key that
+// differ between reference and any of the other versions.
+func (g producerGraph) addChangedOutputs(key string, reference *pb.SpawnExec, others []*pb.SpawnExec) {
+	for _, other := range others {
+		for _, path := range changedPaths(reference.ActualOutputs, other.ActualOutputs) {
+			g[path] = key
+		}
+	}
+}
+
+// classify labels d as an origin of non-determinism or as propagated from
+// upstream actions, filling in d.cause and d.upstream.
+func (g producerGraph) classify(d *diffResult) {
+	d.cause = causeOrigin
+	d.upstream = nil
+
+	differsInInputs := false
+	for _, section := range d.sections {
+		switch section {
+		case "inputs":
+			differsInInputs = true
+		case "listed_outputs", "actual_outputs":
+		default:
+			// The command line, environment or platform changed here.
+			return
+		}
+	}
+	if !differsInInputs {
+		return
+	}
+
+	upstream := make(map[string]bool)
+	for _, outlier := range d.outlierExecs {
+		for _, path := range changedPaths(d.a.Inputs, outlier.Inputs) {
+			producer, ok := g[path]
+			if !ok || producer == d.key {
+				// A source file or an input nothing upstream explains.
+				return
+			}
+			upstream[producer] = true
+		}
+	}
+
+	d.cause = causePropagated
+	for key := range upstream {
+		d.upstream = append(d.upstream, key)
+	}
+	sort.Strings(d.upstream)
+}
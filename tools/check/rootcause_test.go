@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+// consumer returns a remotable Genrule SpawnExec reading input (with inHash)
+// and writing output (with outHash).
+func consumer(input, inHash, output, outHash string) *pb.SpawnExec {
+	exec := genrule(output, outHash)
+	exec.Inputs = []*pb.File{{Path: input, Digest: &pb.Digest{Hash: inHash, SizeBytes: 10}}}
+	return exec
+}
+
+func TestRootCause_Cascade(t *testing.T) {
+	// example_file0 is non-deterministic; example_file1 and uses_example_file
+	// only differ because they consume its changed output.
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{
+		genrule("out/example_file0", "aaa"),
+		consumer("out/example_file0", "aaa", "out/example_file1", "bbb"),
+		consumer("out/example_file1", "bbb", "out/uses_example_file", "ccc"),
+	})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{
+		genrule("out/example_file0", "xxx"),
+		consumer("out/example_file0", "xxx", "out/example_file1", "yyy"),
+		consumer("out/example_file1", "yyy", "out/uses_example_file", "zzz"),
+	})
+
+	var code int
+	out := captureStdout(t, func() {
+		code = run([]string{log1, log2}, "", false)
+	})
+	if code != exitNonDeterministic {
+		t.Errorf("got exit code %d, want %d", code, exitNonDeterministic)
+	}
+	for _, want := range []string{
+		"Non-deterministic actions found: 3 (1 origin, 2 propagated)",
+		"out/example_file0 [Genrule]\n    differs in: actual_outputs\n    root cause: origin",
+		"out/example_file1 [Genrule]\n    differs in: inputs, actual_outputs\n    root cause: propagated from out/example_file0",
+		"out/uses_example_file [Genrule]\n    differs in: inputs, actual_outputs\n    root cause: propagated from out/example_file1",
+		"Summary: 3 paired actions compared, 3 non-deterministic (1 origin, 2 propagated)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "Origins of non-determinism") > strings.Index(out, "Propagated from upstream actions") {
+		t.Errorf("origins should be listed before propagated actions:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		code = runWithOptions([]string{log1, log2}, options{originsOnly: true})
+	})
+	if code != exitNonDeterministic {
+		t.Errorf("--origins_only: got exit code %d, want %d", code, exitNonDeterministic)
+	}
+	if strings.Contains(out, "out/uses_example_file [Genrule]") {
+		t.Errorf("--origins_only should hide propagated actions:\n%s", out)
+	}
+	if !strings.Contains(out, "(2 propagated action(s) not shown)") {
+		t.Errorf("--origins_only should count hidden actions:\n%s", out)
+	}
+}
+
+func TestRootCause_OwnDifferencesAreOrigin(t *testing.T) {
+	// The consumer's input changed, but so did its environment, so it is an
+	// origin in its own right.
+	dir := t.TempDir()
+	b1 := consumer("out/a.txt", "aaa", "out/b.txt", "bbb")
+	b1.EnvironmentVariables = []*pb.EnvironmentVariable{{Name: "TZ", Value: "UTC"}}
+	b2 := consumer("out/a.txt", "xxx", "out/b.txt", "yyy")
+	b2.EnvironmentVariables = []*pb.EnvironmentVariable{{Name: "TZ", Value: "PST"}}
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{genrule("out/a.txt", "aaa"), b1})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{genrule("out/a.txt", "xxx"), b2})
+
+	out := captureStdout(t, func() {
+		run([]string{log1, log2}, "", false)
+	})
+	if !strings.Contains(out, "Non-deterministic actions found: 2 (2 origin, 0 propagated)") {
+		t.Errorf("expected both actions to be origins:\n%s", out)
+	}
+}
+
+func TestRootCause_SourceInputIsOrigin(t *testing.T) {
+	// An input no action in the logs produced cannot be blamed on upstream.
+	d := diffResult{
+		key:      "out/b.txt",
+		sections: []string{"inputs"},
+		a:        consumer("src/a.txt", "aaa", "out/b.txt", "bbb"),
+	}
+	d.outlierExecs = []*pb.SpawnExec{consumer("src/a.txt", "xxx", "out/b.txt", "bbb")}
+
+	producerGraph{}.classify(&d)
+	if d.cause != causeOrigin {
+		t.Errorf("cause = %q, want %q", d.cause, causeOrigin)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// sarifRule is a SARIF reporting rule, one per kind of non-determinism.
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	FullDescription  sarifMessage `json:"fullDescription"`
+	Help             sarifMessage `json:"help"`
+}
+
+// sarifRules are the rules of the SARIF report. The rule of an action is
+// the first that matches its differing sections.
+var sarifRules = []sarifRule{
+	{
+		ID:               "command_args",
+		Name:             "NonDeterministicCommandLine",
+		ShortDescription: sarifMessage{Text: "Action command line differs between builds"},
+		FullDescription:  sarifMessage{Text: "The command line of the action differs between builds, so its outputs may differ and it cannot be cached."},
+		Help:             sarifMessage{Text: "Look for arguments that depend on the time, the machine or the order of a set, and make them stable."},
+	},
+	{
+		ID:               "env",
+		Name:             "NonDeterministicEnvironment",
+		ShortDescription: sarifMessage{Text: "Action environment differs between builds"},
+		FullDescription:  sarifMessage{Text: "The environment variables of the action differ between builds, usually because of --action_env or use_default_shell_env."},
+		Help:             sarifMessage{Text: "Avoid passing machine-specific variables such as USER, HOSTNAME or PATH to actions."},
+	},
+	{
+		ID:               "platform",
+		Name:             "NonDeterministicPlatform",
+		ShortDescription: sarifMessage{Text: "Action execution platform differs between builds"},
+		FullDescription:  sarifMessage{Text: "The execution platform properties of the action differ between builds."},
+		Help:             sarifMessage{Text: "Compare the builds on the same execution platform, or make the platform properties match."},
+	},
+	{
+		ID:               "inputs",
+		Name:             "NonDeterministicInputs",
+		ShortDescription: sarifMessage{Text: "Action inputs differ between builds"},
+		FullDescription:  sarifMessage{Text: "The inputs of the action differ between builds. This is usually propagated from a non-deterministic upstream action."},
+		Help:             sarifMessage{Text: "Fix the upstream action that produces the differing inputs."},
+	},
+	{
+		ID:               "output-only",
+		Name:             "NonDeterministicOutputs",
+		ShortDescription: sarifMessage{Text: "Action outputs differ between builds with identical inputs"},
+		FullDescription:  sarifMessage{Text: "The action ran with the same command line, environment and inputs but produced different outputs, so the tool it runs is non-deterministic."},
+		Help:             sarifMessage{Text: "Look for timestamps, absolute paths, user or host names and random data in the outputs; --disk_cache diffs their content."},
+	},
+}
+
+// sarifRuleIndex returns the index in sarifRules of the rule for d.
+// Differing params files count as a differing command line.
+func sarifRuleIndex(d diffResult) int {
+	for i, section := range []string{"command_args", "environment_variables", "platform", "inputs"} {
+		for _, s := range d.sections {
+			if s == section || (s == paramsSection && section == "command_args") {
+				return i
+			}
+		}
+	}
+	return len(sarifRules) - 1
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+	Properties   sarifProperties    `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification"`
+}
+
+type sarifProperties struct {
+	Key          string   `json:"key"`
+	Mnemonic     string   `json:"mnemonic"`
+	Category     string   `json:"category"`
+	Sections     []string `json:"sections"`
+	LikelyCauses []string `json:"likelyCauses,omitempty"`
+	NoMajority   bool     `json:"noMajority,omitempty"`
+}
+
+// labelPackage returns the package path of a label in the main repository,
+// e.g. "pkg/sub" for "//pkg/sub:target", and the target name. It returns
+// false for labels in external repositories.
+func labelPackage(label string) (pkg, name string, ok bool) {
+	for _, prefix := range []string{"@@//", "@//", "//"} {
+		if rest, found := strings.CutPrefix(label, prefix); found {
+			pkg, name, found = strings.Cut(rest, ":")
+			if !found {
+				name = path.Base(pkg)
+			}
+			return pkg, name, true
+		}
+	}
+	return "", "", false
+}
+
+// rootFiles are the files at the workspace root that findings are located
+// on when their BUILD file cannot be resolved, in order of preference.
+var rootFiles = []string{"MODULE.bazel", "WORKSPACE.bazel", "WORKSPACE", "BUILD.bazel", "BUILD"}
+
+// buildFileLocation resolves label to the BUILD file of its package under
+// workspace and the line that declares the target, if it can be found.
+// Otherwise it falls back to a file at the workspace root, since code
+// scanning rejects results without a physical location, and returns nil
+// only if the workspace has none of rootFiles.
+func buildFileLocation(workspace, label string) *sarifPhysicalLocation {
+	if pkg, name, ok := labelPackage(label); ok {
+		for _, file := range []string{"BUILD.bazel", "BUILD"} {
+			rel := path.Join(pkg, file)
+			line, err := targetLine(filepath.Join(workspace, filepath.FromSlash(rel)), name)
+			if err != nil {
+				continue
+			}
+			loc := &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: rel, URIBaseID: "%SRCROOT%"}}
+			if line > 0 {
+				loc.Region = &sarifRegion{StartLine: line}
+			}
+			return loc
+		}
+	}
+	for _, file := range rootFiles {
+		if _, err := os.Stat(filepath.Join(workspace, file)); err == nil {
+			return &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: file, URIBaseID: "%SRCROOT%"}}
+		}
+	}
+	return nil
+}
+
+// targetLine returns the line of the BUILD file at path that declares the
+// target name, or 0 if none does.
+func targetLine(path, name string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.ReplaceAll(scanner.Text(), " ", "")
+		if strings.Contains(text, `name="`+name+`"`) || strings.Contains(text, `name='`+name+`'`) {
+			return line, nil
+		}
+	}
+	return 0, nil
+}
+
+// sarifLevels maps report categories to SARIF levels.
+var sarifLevels = map[string]string{
+	"origin":        "error",
+	"propagated":    "warning",
+	"ordering_only": "note",
+}
+
+func newSARIFResult(d diffResult, workspace string) sarifResult {
+	rule := sarifRuleIndex(d)
+	message := fmt.Sprintf("%s [%s] is non-deterministic: differs in %s", d.key, d.mnemonic, strings.Join(d.sections, ", "))
+	if d.cause == causePropagated {
+		message += fmt.Sprintf(" (propagated from %s)", strings.Join(d.upstream, ", "))
+	}
+	if d.noMajority {
+		message += " (no majority across logs)"
+	}
+	result := sarifResult{
+		RuleID:    sarifRules[rule].ID,
+		RuleIndex: rule,
+		Level:     sarifLevels[category(d)],
+		Message:   sarifMessage{Text: message},
+		Properties: sarifProperties{
+			Key:          d.key,
+			Mnemonic:     d.mnemonic,
+			Category:     category(d),
+			Sections:     d.sections,
+			LikelyCauses: d.likelyCauses,
+			NoMajority:   d.noMajority,
+		},
+	}
+	// Results are located on the BUILD file of their target or, failing that,
+	// on a file at the workspace root. If neither is found, the result is
+	// emitted without locations rather than with a location code scanning
+	// would reject.
+	if physical := buildFileLocation(workspace, d.targetLabel); physical != nil {
+		location := sarifLocation{PhysicalLocation: physical}
+		if d.targetLabel != "" {
+			location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: d.targetLabel, Kind: "module"}}
+		} else {
+			location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: d.key, Kind: "resource"}}
+		}
+		result.Locations = []sarifLocation{location}
+	}
+	if sup := d.suppressedBy; sup != nil {
+		result.Suppressions = []sarifSuppression{{Kind: "external", Justification: fmt.Sprintf("%s (owner: %s)", sup.Reason, sup.Owner)}}
+	}
+	return result
+}
+
+// writeSARIFReport writes r to w as a SARIF 2.1.0 log. Findings are located
+// on the BUILD file of their target under workspace, when it exists.
+// Suppressed actions are included with a suppression.
+func writeSARIFReport(w io.Writer, r *checkResult, workspace string) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "check", Rules: sarifRules}},
+		Results: []sarifResult{},
+	}
+	for _, d := range r.reported {
+		run.Results = append(run.Results, newSARIFResult(d, workspace))
+	}
+	for _, d := range r.suppressed {
+		run.Results = append(run.Results, newSARIFResult(d, workspace))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestLabelPackage(t *testing.T) {
+	tests := []struct {
+		label, pkg, name string
+		ok               bool
+	}{
+		{"//example:leaks_user", "example", "leaks_user", true},
+		{"@@//tools/check:check_lib", "tools/check", "check_lib", true},
+		{"@//pkg/sub", "pkg/sub", "sub", true},
+		{"//:root", "", "root", true},
+		{"@rules_go//go/tools:builder", "", "", false},
+	}
+	for _, tt := range tests {
+		pkg, name, ok := labelPackage(tt.label)
+		if pkg != tt.pkg || name != tt.name || ok != tt.ok {
+			t.Errorf("labelPackage(%q) = %q, %q, %v; want %q, %q, %v", tt.label, pkg, name, ok, tt.pkg, tt.name, tt.ok)
+		}
+	}
+}
+
+func TestSARIFReport(t *testing.T) {
+	dir := t.TempDir()
+	workspace := filepath.Join(dir, "ws")
+	if err := os.MkdirAll(filepath.Join(workspace, "example"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	build := "genrule(\n    name = \"other\",\n)\n\ngenrule(\n    name = \"leaks_user\",\n    outs = [\"leaks_user.txt\"],\n)\n"
+	if err := os.WriteFile(filepath.Join(workspace, "example", "BUILD.bazel"), []byte(build), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "MODULE.bazel"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputOnly := genrule("out/leaks_user.txt", "aaa")
+	outputOnly.TargetLabel = "//example:leaks_user"
+	outputOnly2 := genrule("out/leaks_user.txt", "bbb")
+	outputOnly2.TargetLabel = "//example:leaks_user"
+	env := genrule("out/env.txt", "ccc")
+	env.TargetLabel = "@other//pkg:env"
+	env2 := genrule("out/env.txt", "ddd")
+	env2.TargetLabel = "@other//pkg:env"
+	env2.EnvironmentVariables = []*pb.EnvironmentVariable{{Name: "USER", Value: "bob"}}
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{outputOnly, env})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{outputOnly2, env2})
+	path := filepath.Join(dir, "report.sarif")
+
+	captureStdout(t, func() {
+		runWithOptions([]string{log1, log2}, options{sarifReport: path, workspace: workspace})
+	})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Tool.Driver.Rules) != len(sarifRules) {
+		t.Fatalf("unexpected SARIF log: %s", data)
+	}
+	results := make(map[string]sarifResult)
+	for _, r := range log.Runs[0].Results {
+		results[r.Properties.Key] = r
+	}
+
+	r := results["out/leaks_user.txt"]
+	if r.RuleID != "output-only" || r.Level != "error" {
+		t.Errorf("got rule %q level %q, want output-only error", r.RuleID, r.Level)
+	}
+	loc := r.Locations[0].PhysicalLocation
+	if loc == nil || loc.ArtifactLocation.URI != "example/BUILD.bazel" || loc.Region == nil || loc.Region.StartLine != 6 {
+		t.Errorf("expected the target's line in example/BUILD.bazel, got %+v", loc)
+	}
+
+	r = results["out/env.txt"]
+	if r.RuleID != "env" || log.Runs[0].Tool.Driver.Rules[r.RuleIndex].ID != "env" {
+		t.Errorf("got rule %q (index %d), want env", r.RuleID, r.RuleIndex)
+	}
+	loc = r.Locations[0].PhysicalLocation
+	if loc == nil || loc.ArtifactLocation.URI != "MODULE.bazel" || r.Locations[0].LogicalLocations[0].FullyQualifiedName != "@other//pkg:env" {
+		t.Errorf("expected an external label to fall back to the workspace root, got %+v", r.Locations[0])
+	}
+
+	// Without any file at the workspace root there is nothing to locate
+	// the result on.
+	env3 := diffResult{key: "out/env.txt", mnemonic: "Genrule", targetLabel: "@other//pkg:env", sections: []string{"env"}}
+	if r := newSARIFResult(env3, t.TempDir()); len(r.Locations) != 0 {
+		t.Errorf("expected no locations, got %+v", r.Locations)
+	}
+}
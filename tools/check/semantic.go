@@ -0,0 +1,70 @@
+package main
+
+import (
+	"sort"
+
+	pb "tools/execlog/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// canonicalize returns a copy of exec with the repeated fields whose order
+// does not affect the action sorted, so that --semantic comparisons treat
+// them as sets: inputs, listed and actual outputs, environment variables and
+// platform properties. Command arguments keep their order.
+func canonicalize(exec *pb.SpawnExec) *pb.SpawnExec {
+	c := proto.Clone(exec).(*pb.SpawnExec)
+	sortFiles(c.Inputs)
+	sortFiles(c.ActualOutputs)
+	sort.Strings(c.ListedOutputs)
+	sort.SliceStable(c.EnvironmentVariables, func(i, j int) bool {
+		return c.EnvironmentVariables[i].Name < c.EnvironmentVariables[j].Name
+	})
+	if c.Platform != nil {
+		props := c.Platform.Properties
+		sort.SliceStable(props, func(i, j int) bool { return props[i].Name < props[j].Name })
+	}
+	return c
+}
+
+func sortFiles(files []*pb.File) {
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+}
+
+// argsPermuted reports whether a and b hold the same arguments, counting
+// duplicates, in a different order.
+func argsPermuted(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int)
+	for _, arg := range a {
+		counts[arg]++
+	}
+	for _, arg := range b {
+		counts[arg]--
+		if counts[arg] < 0 {
+			return false
+		}
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// orderingOnly reports whether the only difference between the majority and
+// every outlier of d is the order of the command arguments, for example a
+// depset flattened in a different order.
+func orderingOnly(d *diffResult) bool {
+	if len(d.sections) != 1 || d.sections[0] != "command_args" {
+		return false
+	}
+	for _, outlier := range d.outlierExecs {
+		if !argsPermuted(d.a.CommandArgs, outlier.CommandArgs) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestArgsPermuted(t *testing.T) {
+	tests := []struct {
+		a, b []string
+		want bool
+	}{
+		{[]string{"cc", "a.o", "b.o"}, []string{"cc", "b.o", "a.o"}, true},
+		{[]string{"cc", "a.o", "b.o"}, []string{"cc", "a.o", "b.o"}, false},
+		{[]string{"cc", "a.o", "a.o"}, []string{"cc", "a.o", "b.o"}, false},
+		{[]string{"cc", "a.o"}, []string{"cc", "a.o", "b.o"}, false},
+	}
+	for _, tt := range tests {
+		if got := argsPermuted(tt.a, tt.b); got != tt.want {
+			t.Errorf("argsPermuted(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSemantic_ReorderedSetsAreEqual(t *testing.T) {
+	a := genrule("out/a.txt", "aaa")
+	a.Inputs = []*pb.File{{Path: "in/x"}, {Path: "in/y"}}
+	a.EnvironmentVariables = []*pb.EnvironmentVariable{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}
+	b := genrule("out/a.txt", "aaa")
+	b.Inputs = []*pb.File{{Path: "in/y"}, {Path: "in/x"}}
+	b.EnvironmentVariables = []*pb.EnvironmentVariable{{Name: "B", Value: "2"}, {Name: "A", Value: "1"}}
+
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{a})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{b})
+
+	var code int
+	captureStdout(t, func() {
+		code = run([]string{log1, log2}, "", false)
+	})
+	if code != exitNonDeterministic {
+		t.Errorf("default mode: got exit code %d, want %d", code, exitNonDeterministic)
+	}
+
+	captureStdout(t, func() {
+		code = runWithOptions([]string{log1, log2}, options{semantic: true})
+	})
+	if code != exitDeterministic {
+		t.Errorf("--semantic: got exit code %d, want %d", code, exitDeterministic)
+	}
+}
+
+func TestSemantic_OrderingOnlyCategory(t *testing.T) {
+	permuted := genrule("out/link", "aaa")
+	permuted.CommandArgs = []string{"ld", "a.o", "b.o"}
+	permuted2 := genrule("out/link", "aaa")
+	permuted2.CommandArgs = []string{"ld", "b.o", "a.o"}
+
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{permuted, genrule("out/stamp", "111")})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{permuted2, genrule("out/stamp", "222")})
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runWithOptions([]string{log1, log2}, options{semantic: true})
+	})
+	if code != exitNonDeterministic {
+		t.Errorf("got exit code %d, want %d", code, exitNonDeterministic)
+	}
+	for _, want := range []string{
+		"Non-deterministic actions found: 2 (1 origin, 0 propagated, 1 ordering-only)",
+		"Origins of non-determinism: 1\n  out/stamp [Genrule]",
+		"Ordering-only differences: 1\n  out/link [Genrule]\n    differs in: command_args (ordering only)",
+		"Summary: 2 paired actions compared, 2 non-deterministic (1 origin, 0 propagated, 1 ordering-only)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	execlog "tools/execlog/lib"
+	pb "tools/execlog/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// streamingRunBytes is the amount of each log --streaming sorts in memory
+// before spilling it to a temporary file.
+var streamingRunBytes = execlog.DefaultRunBytes
+
+// sortLog sorts the log at path by pairing key into temporary files. The
+// caller closes the returned parser to remove them. It also returns the
+// warning about fields unknown to this version of spawn.proto, if any.
+func sortLog(path string, opts options) (*execlog.SortingParser, string, error) {
+	log, err := execlog.OpenLog(path, opts.runner)
+	if err != nil {
+		return nil, "", fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer log.Close()
+
+	var parser execlog.Parser = log
+	if opts.normalizer != nil {
+		parser = execlog.NewNormalizingParser(opts.normalizer, parser)
+	}
+	sorted, err := execlog.NewSortingParser(parser, opts.pairBy.NewKeyer(), opts.tempDir, streamingRunBytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("sorting %s: %w", path, err)
+	}
+	return sorted, log.UnknownFieldsWarning(), nil
+}
+
+// streamActions sorts every log by key on disk, up to opts.jobs logs at
+// once, and merge-joins them, calling compare with the actions of every key
+// in key order. Only the actions of the keys being compared are held in
+// memory.
+func streamActions(paths []string, opts options, compare func(key string, execs []*pb.SpawnExec)) ([]duplicateKey, error) {
+	logs := make([]*execlog.SortingParser, len(paths))
+	defer func() {
+		for _, log := range logs {
+			if log != nil {
+				log.Close()
+			}
+		}
+	}()
+	warnings := make([]string, len(paths))
+	errs := make([]error, len(paths))
+	forEach(opts.jobs, len(paths), func(i int) {
+		logs[i], warnings[i], errs[i] = sortLog(paths[i], opts)
+	})
+	// heads[i] is the next action of log i, or nil at its end.
+	heads := make([]*pb.SpawnExec, len(paths))
+	for i, path := range paths {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if warnings[i] != "" {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", path, warnings[i])
+		}
+		var err error
+		if heads[i], err = logs[i].Next(); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+
+	var duplicates []duplicateKey
+	for {
+		var key string
+		done := true
+		for i, head := range heads {
+			if head != nil && (done || logs[i].Key() < key) {
+				key, done = logs[i].Key(), false
+			}
+		}
+		if done {
+			return duplicates, nil
+		}
+
+		group := make([]map[string][]*pb.SpawnExec, len(logs))
+		for i, log := range logs {
+			group[i] = make(map[string][]*pb.SpawnExec)
+			for heads[i] != nil && log.Key() == key {
+				group[i][key] = append(group[i][key], heads[i])
+				var err error
+				if heads[i], err = log.Next(); err != nil {
+					return nil, fmt.Errorf("parsing %s: %w", paths[i], err)
+				}
+			}
+		}
+
+		resolved, dups := resolveDuplicates(group)
+		duplicates = append(duplicates, dups...)
+		var keys []string
+		for _, actions := range resolved {
+			for k := range actions {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for n, k := range keys {
+			if n > 0 && k == keys[n-1] {
+				continue
+			}
+			execs := make([]*pb.SpawnExec, len(resolved))
+			for i, actions := range resolved {
+				execs[i] = actions[k]
+			}
+			compare(k, execs)
+		}
+	}
+}
+
+// actionSpill keeps actions in a temporary file, so that only their offset
+// and size are held in memory.
+type actionSpill struct {
+	f    *os.File
+	w    *bufio.Writer
+	size int64
+}
+
+// newActionSpill creates the temporary file in dir, "" for the default
+// temporary directory. Close removes it.
+func newActionSpill(dir string) (*actionSpill, error) {
+	f, err := os.CreateTemp(dir, "check-actions-*")
+	if err != nil {
+		return nil, err
+	}
+	return &actionSpill{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+// add appends exec to the file and returns where to read it back from.
+func (s *actionSpill) add(exec *pb.SpawnExec) (int64, int, error) {
+	data, err := proto.Marshal(exec)
+	if err != nil {
+		return 0, 0, fmt.Errorf("encoding record: %w", err)
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return 0, 0, fmt.Errorf("writing %s: %w", s.f.Name(), err)
+	}
+	offset := s.size
+	s.size += int64(len(data))
+	return offset, len(data), nil
+}
+
+// read returns the action added at offset with size bytes.
+func (s *actionSpill) read(offset int64, size int) (*pb.SpawnExec, error) {
+	if err := s.w.Flush(); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", s.f.Name(), err)
+	}
+	data := make([]byte, size)
+	if _, err := s.f.ReadAt(data, offset); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("reading %s: %w", s.f.Name(), err)
+	}
+	exec := &pb.SpawnExec{}
+	if err := proto.Unmarshal(data, exec); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", s.f.Name(), err)
+	}
+	return exec, nil
+}
+
+// Close removes the file.
+func (s *actionSpill) Close() error {
+	s.f.Close()
+	return os.Remove(s.f.Name())
+}
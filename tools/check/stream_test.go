@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestStreaming_MatchesInMemory(t *testing.T) {
+	defer func(n int) { streamingRunBytes = n }(streamingRunBytes)
+	// Spill every record or two, so logs are merged from several runs.
+	streamingRunBytes = 100
+
+	dir := t.TempDir()
+	labelled := func(output, hash string) *pb.SpawnExec {
+		exec := genrule(output, hash)
+		exec.TargetLabel = "//pkg:gen"
+		return exec
+	}
+	// The resolved duplicates "out/a#1" and "out/a#2" must be reported in
+	// the same order as in memory, next to "out/a!".
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{
+		genrule("out/z.txt", "zzz"),
+		genrule("out/a", "aaa"),
+		genrule("out/a!", "bbb"),
+		genrule("out/a", "ccc"),
+		genrule("out/only1.txt", "ddd"),
+		labelled("out/gen-1a2b3c4d.txt", "eee"),
+	})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{
+		genrule("out/a", "aaa"),
+		genrule("out/a", "xxx"),
+		genrule("out/a!", "ggg"),
+		genrule("out/z.txt", "yyy"),
+		labelled("out/gen-9f8e7d6c.txt", "eee"),
+	})
+	log3 := writeLogs(t, dir, "log3.bin", []*pb.SpawnExec{
+		genrule("out/z.txt", "zzz"),
+		genrule("out/only3.txt", "fff"),
+	})
+	paths := []string{log1, log2, log3}
+
+	for _, format := range []string{"text", "json"} {
+		want := captureStdout(t, func() {
+			runWithOptions(paths, options{outputFormat: format, verbose: true})
+		})
+		got := captureStdout(t, func() {
+			runWithOptions(paths, options{outputFormat: format, verbose: true, streaming: true})
+		})
+		if got != want {
+			t.Errorf("%s: streaming output differs:\n%s\nin memory:\n%s", format, got, want)
+		}
+	}
+}
+
+func TestStreaming_TempDir(t *testing.T) {
+	dir := t.TempDir()
+	log1 := writeLogs(t, dir, "log1.bin", []*pb.SpawnExec{genrule("out/a.txt", "aaa"), genrule("out/only1.txt", "bbb")})
+	log2 := writeLogs(t, dir, "log2.bin", []*pb.SpawnExec{genrule("out/a.txt", "ccc")})
+	paths := []string{log1, log2}
+
+	tempDir := t.TempDir()
+	var code int
+	captureStdout(t, func() {
+		code = runWithOptions(paths, options{streaming: true, tempDir: tempDir})
+	})
+	if code != exitNonDeterministic {
+		t.Errorf("got exit code %d, want %d", code, exitNonDeterministic)
+	}
+	if entries, _ := os.ReadDir(tempDir); len(entries) != 0 {
+		t.Errorf("expected the temporary files to be removed, found %d", len(entries))
+	}
+
+	captureStdout(t, func() {
+		code = runWithOptions(paths, options{streaming: true, tempDir: filepath.Join(tempDir, "missing")})
+	})
+	if code != exitUsageError {
+		t.Errorf("got exit code %d for a missing --temp_dir, want %d", code, exitUsageError)
+	}
+}
@@ -1,5 +1,8 @@
 module tools/execlog
 
-go 1.21
+go 1.22
 
-require google.golang.org/protobuf v1.36.3
+require (
+	github.com/klauspost/compress v1.18.0
+	google.golang.org/protobuf v1.36.3
+)
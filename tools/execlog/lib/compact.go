@@ -0,0 +1,244 @@
+package execlog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	pb "tools/execlog/proto"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// CompactParser reads Bazel's compact execution log (--execution_log_compact_file)
+// and reconstructs full SpawnExec records from its interned entries.
+type CompactParser struct {
+	reader           *bufio.Reader
+	decoder          *zstd.Decoder
+	restrictToRunner string
+	hashFunctionName string
+	entries          map[uint32]*pb.ExecLogEntry
+}
+
+// NewCompactParser creates a parser that decompresses the zstd stream in r
+// and reconstructs SpawnExec messages from the ExecLogEntry records it
+// contains, optionally filtering to only those with the given runner.
+func NewCompactParser(r io.Reader, restrictToRunner string) (*CompactParser, error) {
+	decoder, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	p := newCompactParser(decoder, restrictToRunner)
+	p.decoder = decoder
+	return p, nil
+}
+
+// newCompactParser creates a parser over an already decompressed stream of
+// varint-delimited ExecLogEntry messages.
+func newCompactParser(r io.Reader, restrictToRunner string) *CompactParser {
+	return &CompactParser{
+		reader:           bufio.NewReader(r),
+		restrictToRunner: restrictToRunner,
+		entries:          make(map[uint32]*pb.ExecLogEntry),
+	}
+}
+
+// Close releases the zstd decoder. It is called automatically once the
+// stream is exhausted, so callers only need it when stopping early.
+func (p *CompactParser) Close() {
+	if p.decoder != nil {
+		p.decoder.Close()
+		p.decoder = nil
+	}
+}
+
+func (p *CompactParser) Next() (*pb.SpawnExec, error) {
+	for {
+		entry := &pb.ExecLogEntry{}
+		err := protodelim.UnmarshalFrom(p.reader, entry)
+		if err == io.EOF {
+			p.Close()
+			return nil, nil
+		}
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+
+		if entry.Id != 0 {
+			p.entries[entry.Id] = entry
+		}
+
+		switch t := entry.Type.(type) {
+		case *pb.ExecLogEntry_Invocation_:
+			p.hashFunctionName = t.Invocation.HashFunctionName
+		case *pb.ExecLogEntry_Spawn_:
+			if p.restrictToRunner != "" && t.Spawn.Runner != p.restrictToRunner {
+				continue
+			}
+			return p.reconstruct(t.Spawn)
+		}
+	}
+}
+
+// reconstruct builds a SpawnExec from a compact Spawn entry, resolving its
+// input set and outputs against previously seen entries.
+func (p *CompactParser) reconstruct(spawn *pb.ExecLogEntry_Spawn) (*pb.SpawnExec, error) {
+	inputs, err := p.expandInputSet(spawn.InputSetId)
+	if err != nil {
+		return nil, err
+	}
+	tools, err := p.expandInputSet(spawn.ToolSetId)
+	if err != nil {
+		return nil, err
+	}
+	isTool := make(map[string]bool, len(tools))
+	for _, f := range tools {
+		isTool[f.Path] = true
+	}
+	for _, f := range inputs {
+		f.IsTool = isTool[f.Path]
+	}
+
+	exec := &pb.SpawnExec{
+		CommandArgs:          spawn.Args,
+		EnvironmentVariables: spawn.EnvVars,
+		Platform:             spawn.Platform,
+		Inputs:               inputs,
+		Remotable:            spawn.Remotable,
+		Cacheable:            spawn.Cacheable,
+		RemoteCacheable:      spawn.RemoteCacheable,
+		TimeoutMillis:        spawn.TimeoutMillis,
+		Mnemonic:             spawn.Mnemonic,
+		Runner:               spawn.Runner,
+		RemoteCacheHit:       spawn.CacheHit,
+		Status:               spawn.Status,
+		ExitCode:             spawn.ExitCode,
+		TargetLabel:          spawn.TargetLabel,
+		Digest:               p.digest(spawn.Digest),
+		Metrics:              spawn.Metrics,
+	}
+
+	for _, output := range spawn.Outputs {
+		var id uint32
+		switch t := output.Type.(type) {
+		case *pb.ExecLogEntry_Output_InvalidOutputPath:
+			exec.ListedOutputs = append(exec.ListedOutputs, t.InvalidOutputPath)
+			continue
+		case *pb.ExecLogEntry_Output_OutputId:
+			id = t.OutputId
+		case *pb.ExecLogEntry_Output_FileId:
+			id = t.FileId
+		case *pb.ExecLogEntry_Output_DirectoryId:
+			id = t.DirectoryId
+		case *pb.ExecLogEntry_Output_UnresolvedSymlinkId:
+			id = t.UnresolvedSymlinkId
+		default:
+			continue
+		}
+		entry, ok := p.entries[id]
+		if !ok {
+			return nil, fmt.Errorf("spawn %q references unknown output entry %d", spawn.Mnemonic, id)
+		}
+		switch t := entry.Type.(type) {
+		case *pb.ExecLogEntry_File_:
+			exec.ListedOutputs = append(exec.ListedOutputs, t.File.Path)
+			exec.ActualOutputs = append(exec.ActualOutputs, p.file(t.File.Path, t.File.Digest))
+		case *pb.ExecLogEntry_Directory_:
+			exec.ListedOutputs = append(exec.ListedOutputs, t.Directory.Path)
+			exec.ActualOutputs = append(exec.ActualOutputs, p.directoryFiles(t.Directory)...)
+		case *pb.ExecLogEntry_UnresolvedSymlink_:
+			exec.ListedOutputs = append(exec.ListedOutputs, t.UnresolvedSymlink.Path)
+			exec.ActualOutputs = append(exec.ActualOutputs, symlinkFile(t.UnresolvedSymlink))
+		}
+	}
+	return exec, nil
+}
+
+// expandInputSet flattens an input set and its transitive sets into a list
+// of files sorted by path, matching the order of the binary log.
+func (p *CompactParser) expandInputSet(id uint32) ([]*pb.File, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	byPath := make(map[string]*pb.File)
+	visited := make(map[uint32]bool)
+	stack := []uint32{id}
+	for len(stack) > 0 {
+		setID := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[setID] {
+			continue
+		}
+		visited[setID] = true
+
+		entry, ok := p.entries[setID]
+		if !ok || entry.GetInputSet() == nil {
+			return nil, fmt.Errorf("unknown input set entry %d", setID)
+		}
+		set := entry.GetInputSet()
+
+		var ids []uint32
+		ids = append(ids, set.InputIds...)
+		ids = append(ids, set.FileIds...)
+		ids = append(ids, set.DirectoryIds...)
+		ids = append(ids, set.UnresolvedSymlinkIds...)
+		for _, inputID := range ids {
+			input, ok := p.entries[inputID]
+			if !ok {
+				return nil, fmt.Errorf("input set %d references unknown entry %d", setID, inputID)
+			}
+			switch t := input.Type.(type) {
+			case *pb.ExecLogEntry_File_:
+				byPath[t.File.Path] = p.file(t.File.Path, t.File.Digest)
+			case *pb.ExecLogEntry_Directory_:
+				for _, f := range p.directoryFiles(t.Directory) {
+					byPath[f.Path] = f
+				}
+			case *pb.ExecLogEntry_UnresolvedSymlink_:
+				byPath[t.UnresolvedSymlink.Path] = symlinkFile(t.UnresolvedSymlink)
+			}
+		}
+		stack = append(stack, set.TransitiveSetIds...)
+	}
+
+	files := make([]*pb.File, 0, len(byPath))
+	for _, f := range byPath {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// directoryFiles returns the files contained in a directory entry, with paths
+// relative to the execution root.
+func (p *CompactParser) directoryFiles(dir *pb.ExecLogEntry_Directory) []*pb.File {
+	files := make([]*pb.File, 0, len(dir.Files))
+	for _, f := range dir.Files {
+		files = append(files, p.file(dir.Path+"/"+f.Path, f.Digest))
+	}
+	return files
+}
+
+// file builds a File, filling in the digest function recorded by the
+// invocation entry since compact digests omit it.
+func (p *CompactParser) file(path string, digest *pb.Digest) *pb.File {
+	return &pb.File{Path: path, Digest: p.digest(digest)}
+}
+
+// digest copies a compact digest, adding the invocation's hash function name.
+func (p *CompactParser) digest(digest *pb.Digest) *pb.Digest {
+	if digest == nil {
+		return nil
+	}
+	return &pb.Digest{
+		Hash:             digest.Hash,
+		SizeBytes:        digest.SizeBytes,
+		HashFunctionName: p.hashFunctionName,
+	}
+}
+
+func symlinkFile(symlink *pb.ExecLogEntry_UnresolvedSymlink) *pb.File {
+	return &pb.File{Path: symlink.Path, SymlinkTargetPath: symlink.TargetPath}
+}
@@ -0,0 +1,258 @@
+package execlog
+
+import (
+	"bytes"
+	"testing"
+
+	pb "tools/execlog/proto"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// writeCompact writes zstd-compressed varint-delimited ExecLogEntry messages to buf.
+func writeCompact(t *testing.T, buf *bytes.Buffer, entries []*pb.ExecLogEntry) {
+	t.Helper()
+	enc, err := zstd.NewWriter(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if _, err := protodelim.MarshalTo(enc, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func compactFile(id uint32, path, hash string) *pb.ExecLogEntry {
+	return &pb.ExecLogEntry{
+		Id: id,
+		Type: &pb.ExecLogEntry_File_{File: &pb.ExecLogEntry_File{
+			Path:   path,
+			Digest: &pb.Digest{Hash: hash, SizeBytes: 10},
+		}},
+	}
+}
+
+func compactInputSet(id uint32, inputIDs, transitiveIDs []uint32) *pb.ExecLogEntry {
+	return &pb.ExecLogEntry{
+		Id: id,
+		Type: &pb.ExecLogEntry_InputSet_{InputSet: &pb.ExecLogEntry_InputSet{
+			InputIds:         inputIDs,
+			TransitiveSetIds: transitiveIDs,
+		}},
+	}
+}
+
+func compactSpawn(mnemonic, runner string, inputSetID uint32, outputIDs ...uint32) *pb.ExecLogEntry {
+	spawn := &pb.ExecLogEntry_Spawn{
+		Args:        []string{"/bin/true"},
+		Mnemonic:    mnemonic,
+		Runner:      runner,
+		InputSetId:  inputSetID,
+		Remotable:   true,
+		Cacheable:   true,
+		TargetLabel: "//pkg:" + mnemonic,
+	}
+	for _, id := range outputIDs {
+		spawn.Outputs = append(spawn.Outputs, &pb.ExecLogEntry_Output{
+			Type: &pb.ExecLogEntry_Output_OutputId{OutputId: id},
+		})
+	}
+	return &pb.ExecLogEntry{Type: &pb.ExecLogEntry_Spawn_{Spawn: spawn}}
+}
+
+func TestCompactParser_Reconstruct(t *testing.T) {
+	var buf bytes.Buffer
+	writeCompact(t, &buf, []*pb.ExecLogEntry{
+		{Type: &pb.ExecLogEntry_Invocation_{Invocation: &pb.ExecLogEntry_Invocation{HashFunctionName: "SHA-256"}}},
+		compactFile(1, "in/b.txt", "bbb"),
+		compactFile(2, "in/a.txt", "aaa"),
+		compactInputSet(3, []uint32{1}, nil),
+		{
+			Id: 4,
+			Type: &pb.ExecLogEntry_Directory_{Directory: &pb.ExecLogEntry_Directory{
+				Path: "in/dir",
+				Files: []*pb.ExecLogEntry_File{
+					{Path: "c.txt", Digest: &pb.Digest{Hash: "ccc", SizeBytes: 3}},
+				},
+			}},
+		},
+		compactInputSet(5, []uint32{2, 4}, []uint32{3}),
+		compactFile(6, "out/x.txt", "xxx"),
+		compactSpawn("Genrule", "linux-sandbox", 5, 6),
+	})
+
+	parser, err := NewCompactParser(&buf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec, err := parser.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exec == nil {
+		t.Fatal("expected a SpawnExec, got nil")
+	}
+
+	var inputs []string
+	for _, f := range exec.Inputs {
+		inputs = append(inputs, f.Path)
+	}
+	wantInputs := []string{"in/a.txt", "in/b.txt", "in/dir/c.txt"}
+	if len(inputs) != len(wantInputs) {
+		t.Fatalf("inputs = %v, want %v", inputs, wantInputs)
+	}
+	for i := range wantInputs {
+		if inputs[i] != wantInputs[i] {
+			t.Errorf("inputs[%d] = %q, want %q", i, inputs[i], wantInputs[i])
+		}
+	}
+	if got := exec.Inputs[0].Digest.HashFunctionName; got != "SHA-256" {
+		t.Errorf("hash function = %q, want %q", got, "SHA-256")
+	}
+
+	if len(exec.ListedOutputs) != 1 || exec.ListedOutputs[0] != "out/x.txt" {
+		t.Errorf("listed outputs = %v, want [out/x.txt]", exec.ListedOutputs)
+	}
+	if len(exec.ActualOutputs) != 1 || exec.ActualOutputs[0].Digest.Hash != "xxx" {
+		t.Errorf("actual outputs = %v, want out/x.txt with hash xxx", exec.ActualOutputs)
+	}
+	if exec.Mnemonic != "Genrule" || exec.TargetLabel != "//pkg:Genrule" || !exec.Remotable {
+		t.Errorf("spawn metadata not copied: %v", exec)
+	}
+
+	next, err := parser.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != nil {
+		t.Errorf("expected nil at EOF, got %v", next)
+	}
+}
+
+func TestCompactParser_DirectoryOutput(t *testing.T) {
+	var buf bytes.Buffer
+	writeCompact(t, &buf, []*pb.ExecLogEntry{
+		{
+			Id: 1,
+			Type: &pb.ExecLogEntry_Directory_{Directory: &pb.ExecLogEntry_Directory{
+				Path: "out/tree",
+				Files: []*pb.ExecLogEntry_File{
+					{Path: "a", Digest: &pb.Digest{Hash: "a1"}},
+					{Path: "b", Digest: &pb.Digest{Hash: "b1"}},
+				},
+			}},
+		},
+		compactSpawn("TreeAction", "", 0, 1),
+	})
+
+	parser, err := NewCompactParser(&buf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec, err := parser.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exec.ListedOutputs) != 1 || exec.ListedOutputs[0] != "out/tree" {
+		t.Errorf("listed outputs = %v, want [out/tree]", exec.ListedOutputs)
+	}
+	if len(exec.ActualOutputs) != 2 || exec.ActualOutputs[1].Path != "out/tree/b" {
+		t.Errorf("actual outputs = %v, want out/tree/a and out/tree/b", exec.ActualOutputs)
+	}
+}
+
+func TestCompactParser_WithFilter(t *testing.T) {
+	var buf bytes.Buffer
+	writeCompact(t, &buf, []*pb.ExecLogEntry{
+		compactSpawn("Genrule", "linux-sandbox", 0),
+		compactSpawn("CppCompile", "remote", 0),
+	})
+
+	parser, err := NewCompactParser(&buf, "remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec, err := parser.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exec == nil || exec.Mnemonic != "CppCompile" {
+		t.Fatalf("got %v, want CppCompile", exec)
+	}
+	if exec, _ := parser.Next(); exec != nil {
+		t.Errorf("expected nil at EOF, got %v", exec)
+	}
+}
+
+func TestCompactParser_UnknownInputSet(t *testing.T) {
+	var buf bytes.Buffer
+	writeCompact(t, &buf, []*pb.ExecLogEntry{
+		compactSpawn("Genrule", "", 42),
+	})
+
+	parser, err := NewCompactParser(&buf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.Next(); err == nil {
+		t.Error("expected error for dangling input set reference")
+	}
+}
+
+func TestCompactParser_UpstreamFields(t *testing.T) {
+	spawn := compactSpawn("GoLink", "", 4, 5)
+	spawn.GetSpawn().ToolSetId = 3
+	spawn.GetSpawn().RemoteCacheable = true
+	spawn.GetSpawn().Digest = &pb.Digest{Hash: "spawndigest", SizeBytes: 99}
+	spawn.GetSpawn().Metrics = &pb.SpawnMetrics{InputFiles: 2}
+
+	var buf bytes.Buffer
+	writeCompact(t, &buf, []*pb.ExecLogEntry{
+		{Type: &pb.ExecLogEntry_Invocation_{Invocation: &pb.ExecLogEntry_Invocation{HashFunctionName: "SHA-256"}}},
+		compactFile(1, "tools/linker", "lll"),
+		compactFile(2, "in/main.a", "aaa"),
+		compactInputSet(3, []uint32{1}, nil),
+		compactInputSet(4, []uint32{2}, []uint32{3}),
+		{
+			Id: 5,
+			Type: &pb.ExecLogEntry_UnresolvedSymlink_{UnresolvedSymlink: &pb.ExecLogEntry_UnresolvedSymlink{
+				Path:       "out/link",
+				TargetPath: "bin/main",
+			}},
+		},
+		spawn,
+	})
+
+	parser, err := NewCompactParser(&buf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec, err := parser.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tools := make(map[string]bool)
+	for _, f := range exec.Inputs {
+		tools[f.Path] = f.IsTool
+	}
+	if !tools["tools/linker"] || tools["in/main.a"] {
+		t.Errorf("is_tool = %v, want only tools/linker", tools)
+	}
+	if len(exec.ActualOutputs) != 1 || exec.ActualOutputs[0].SymlinkTargetPath != "bin/main" {
+		t.Errorf("actual outputs = %v, want out/link -> bin/main", exec.ActualOutputs)
+	}
+	if !exec.RemoteCacheable {
+		t.Error("remote_cacheable not copied")
+	}
+	if exec.Digest.GetHash() != "spawndigest" || exec.Digest.GetHashFunctionName() != "SHA-256" {
+		t.Errorf("digest = %v, want spawndigest with SHA-256", exec.Digest)
+	}
+	if exec.Metrics.GetInputFiles() != 2 {
+		t.Errorf("metrics = %v, want input_files 2", exec.Metrics)
+	}
+}
@@ -0,0 +1,213 @@
+package execlog
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	pb "tools/execlog/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultRunBytes is the amount of encoded records a SortingParser sorts in
+// memory before spilling them to a temporary file.
+const DefaultRunBytes = 64 << 20
+
+// keyedRecord is an encoded SpawnExec with its pairing key.
+type keyedRecord struct {
+	key  string
+	data []byte
+}
+
+// SortingParser emits the records of a log sorted by pairing key, records
+// with the same key in log order. Records without a key are dropped. It
+// sorts the log in runs of bounded size that are spilled to temporary files
+// and merged, so memory use does not grow with the size of the log.
+type SortingParser struct {
+	files []*os.File
+	runs  runHeap
+	key   string
+}
+
+// NewSortingParser reads all records from input, keyed by keyer, and spills
+// them to temporary files in dir ("" for the default temporary directory)
+// in sorted runs of about runBytes each. Close removes the files.
+func NewSortingParser(input Parser, keyer *Keyer, dir string, runBytes int) (*SortingParser, error) {
+	p := &SortingParser{}
+	var buffered []keyedRecord
+	var size int
+	for {
+		exec, err := input.Next()
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		if exec != nil {
+			key := keyer.Key(exec)
+			if key == "" {
+				continue
+			}
+			data, err := proto.Marshal(exec)
+			if err != nil {
+				p.Close()
+				return nil, fmt.Errorf("encoding record: %w", err)
+			}
+			buffered = append(buffered, keyedRecord{key, data})
+			size += len(key) + len(data)
+		}
+		if (exec == nil && len(buffered) > 0) || size >= runBytes {
+			if err := p.spill(buffered, dir); err != nil {
+				p.Close()
+				return nil, err
+			}
+			buffered, size = nil, 0
+		}
+		if exec == nil {
+			break
+		}
+	}
+
+	for i, f := range p.files {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			p.Close()
+			return nil, err
+		}
+		r := &run{index: i, reader: bufio.NewReader(f)}
+		ok, err := r.advance()
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("reading %s: %w", f.Name(), err)
+		}
+		if ok {
+			p.runs = append(p.runs, r)
+		}
+	}
+	heap.Init(&p.runs)
+	return p, nil
+}
+
+// spill writes records, sorted by key, to a new temporary file.
+func (p *SortingParser) spill(records []keyedRecord, dir string) error {
+	sort.SliceStable(records, func(i, j int) bool { return records[i].key < records[j].key })
+	f, err := os.CreateTemp(dir, "execlog-sort-*")
+	if err != nil {
+		return err
+	}
+	p.files = append(p.files, f)
+	w := bufio.NewWriter(f)
+	var buf [binary.MaxVarintLen64]byte
+	for _, r := range records {
+		for _, field := range []string{r.key, string(r.data)} {
+			n := binary.PutUvarint(buf[:], uint64(len(field)))
+			w.Write(buf[:n])
+			w.WriteString(field)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("writing %s: %w", f.Name(), err)
+	}
+	return nil
+}
+
+// Next returns the next record in key order, or (nil, nil) when done.
+func (p *SortingParser) Next() (*pb.SpawnExec, error) {
+	if len(p.runs) == 0 {
+		return nil, nil
+	}
+	r := p.runs[0]
+	exec := &pb.SpawnExec{}
+	if err := proto.Unmarshal(r.record.data, exec); err != nil {
+		return nil, err
+	}
+	p.key = r.record.key
+	ok, err := r.advance()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		heap.Fix(&p.runs, 0)
+	} else {
+		heap.Pop(&p.runs)
+	}
+	return exec, nil
+}
+
+// Key returns the pairing key of the record last returned by Next.
+func (p *SortingParser) Key() string {
+	return p.key
+}
+
+// Close removes the temporary files.
+func (p *SortingParser) Close() error {
+	var first error
+	for _, f := range p.files {
+		f.Close()
+		if err := os.Remove(f.Name()); err != nil && first == nil {
+			first = err
+		}
+	}
+	p.files, p.runs = nil, nil
+	return first
+}
+
+// run reads one sorted spill file. record is its current record.
+type run struct {
+	index  int
+	reader *bufio.Reader
+	record keyedRecord
+}
+
+// advance reads the next record of the run, returning false at its end.
+func (r *run) advance() (bool, error) {
+	key, err := readField(r.reader)
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	data, err := readField(r.reader)
+	if err != nil {
+		return false, err
+	}
+	r.record = keyedRecord{string(key), data}
+	return true, nil
+}
+
+// readField reads a uvarint length followed by that many bytes.
+func readField(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	field := make([]byte, n)
+	if _, err := io.ReadFull(r, field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// runHeap orders runs by their current key. Ties go to the earlier run, which
+// holds earlier records of the log.
+type runHeap []*run
+
+func (h runHeap) Len() int { return len(h) }
+func (h runHeap) Less(i, j int) bool {
+	if h[i].record.key != h[j].record.key {
+		return h[i].record.key < h[j].record.key
+	}
+	return h[i].index < h[j].index
+}
+func (h runHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x interface{}) { *h = append(*h, x.(*run)) }
+func (h *runHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
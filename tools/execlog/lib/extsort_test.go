@@ -0,0 +1,62 @@
+package execlog
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestSortingParser(t *testing.T) {
+	var buf bytes.Buffer
+	for _, e := range []struct{ output, runner string }{
+		{"out/c.txt", "c"},
+		{"out/a.txt", "a1"},
+		{"", "no key"},
+		{"out/b.txt", "b"},
+		{"out/a.txt", "a2"},
+		{"out/d.txt", "d"},
+		{"out/a.txt", "a3"},
+	} {
+		exec := &pb.SpawnExec{Runner: e.runner}
+		if e.output != "" {
+			exec.ListedOutputs = []string{e.output}
+		}
+		writeDelimited(t, &buf, exec)
+	}
+
+	dir := t.TempDir()
+	// A tiny run size spills every record or two to its own file.
+	p, err := NewSortingParser(NewFilteringParser(&buf, ""), PairByFirstOutput.NewKeyer(), dir, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.files) < 3 {
+		t.Errorf("expected several spill files, got %d", len(p.files))
+	}
+
+	want := []struct{ key, runner string }{
+		{"out/a.txt", "a1"}, {"out/a.txt", "a2"}, {"out/a.txt", "a3"},
+		{"out/b.txt", "b"}, {"out/c.txt", "c"}, {"out/d.txt", "d"},
+	}
+	for i, w := range want {
+		exec, err := p.Next()
+		if err != nil || exec == nil {
+			t.Fatalf("index %d: got %v, %v", i, exec, err)
+		}
+		if p.Key() != w.key || exec.Runner != w.runner {
+			t.Errorf("index %d: got %q (%s), want %q (%s)", i, p.Key(), exec.Runner, w.key, w.runner)
+		}
+	}
+	if exec, err := p.Next(); exec != nil || err != nil {
+		t.Errorf("expected the end, got %v, %v", exec, err)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected the spill files to be removed, found %d", len(entries))
+	}
+}
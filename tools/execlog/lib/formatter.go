@@ -3,9 +3,13 @@ package execlog
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	pb "tools/execlog/proto"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // FormatSpawnExec writes a SpawnExec message to w in deterministic text format
@@ -27,11 +31,14 @@ func FormatSpawnExec(w io.Writer, exec *pb.SpawnExec) error {
 		if err := writeStringField(w, "  ", "value", env.Value); err != nil {
 			return err
 		}
+		if err := formatUnknownFields(w, "  ", env.ProtoReflect().GetUnknown()); err != nil {
+			return err
+		}
 		if _, err := fmt.Fprint(w, "}\n"); err != nil {
 			return err
 		}
 	}
-	if exec.Platform != nil && len(exec.Platform.Properties) > 0 {
+	if exec.Platform != nil && (len(exec.Platform.Properties) > 0 || len(exec.Platform.ProtoReflect().GetUnknown()) > 0) {
 		if _, err := fmt.Fprint(w, "platform {\n"); err != nil {
 			return err
 		}
@@ -45,10 +52,16 @@ func FormatSpawnExec(w io.Writer, exec *pb.SpawnExec) error {
 			if err := writeStringField(w, "    ", "value", prop.Value); err != nil {
 				return err
 			}
+			if err := formatUnknownFields(w, "    ", prop.ProtoReflect().GetUnknown()); err != nil {
+				return err
+			}
 			if _, err := fmt.Fprint(w, "  }\n"); err != nil {
 				return err
 			}
 		}
+		if err := formatUnknownFields(w, "  ", exec.Platform.ProtoReflect().GetUnknown()); err != nil {
+			return err
+		}
 		if _, err := fmt.Fprint(w, "}\n"); err != nil {
 			return err
 		}
@@ -95,7 +108,28 @@ func FormatSpawnExec(w io.Writer, exec *pb.SpawnExec) error {
 	if err := writeInt32Field(w, "", "exit_code", exec.ExitCode); err != nil {
 		return err
 	}
-	return nil
+	if err := writeBoolField(w, "", "remote_cacheable", exec.RemoteCacheable); err != nil {
+		return err
+	}
+	if exec.Walltime != nil {
+		if err := formatDuration(w, "", "walltime", exec.Walltime); err != nil {
+			return err
+		}
+	}
+	if err := writeStringField(w, "", "target_label", exec.TargetLabel); err != nil {
+		return err
+	}
+	if exec.Digest != nil {
+		if err := formatDigest(w, "", exec.Digest); err != nil {
+			return err
+		}
+	}
+	if exec.Metrics != nil {
+		if err := formatMetrics(w, "", exec.Metrics); err != nil {
+			return err
+		}
+	}
+	return formatUnknownFields(w, "", exec.ProtoReflect().GetUnknown())
 }
 
 func formatFile(w io.Writer, fieldName, indent string, file *pb.File) error {
@@ -111,6 +145,15 @@ func formatFile(w io.Writer, fieldName, indent string, file *pb.File) error {
 			return err
 		}
 	}
+	if err := writeBoolField(w, innerIndent, "is_tool", file.IsTool); err != nil {
+		return err
+	}
+	if err := writeStringField(w, innerIndent, "symlink_target_path", file.SymlinkTargetPath); err != nil {
+		return err
+	}
+	if err := formatUnknownFields(w, innerIndent, file.ProtoReflect().GetUnknown()); err != nil {
+		return err
+	}
 	if _, err := fmt.Fprintf(w, "%s}\n", indent); err != nil {
 		return err
 	}
@@ -131,12 +174,186 @@ func formatDigest(w io.Writer, indent string, digest *pb.Digest) error {
 	if err := writeStringField(w, innerIndent, "hash_function_name", digest.HashFunctionName); err != nil {
 		return err
 	}
+	if err := formatUnknownFields(w, innerIndent, digest.ProtoReflect().GetUnknown()); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s}\n", indent); err != nil {
+		return err
+	}
+	return nil
+}
+
+func formatMetrics(w io.Writer, indent string, m *pb.SpawnMetrics) error {
+	if _, err := fmt.Fprintf(w, "%smetrics {\n", indent); err != nil {
+		return err
+	}
+	innerIndent := indent + "  "
+	durations := []struct {
+		name  string
+		value *durationpb.Duration
+	}{
+		{"total_time", m.TotalTime},
+		{"parse_time", m.ParseTime},
+		{"network_time", m.NetworkTime},
+		{"fetch_time", m.FetchTime},
+		{"queue_time", m.QueueTime},
+		{"setup_time", m.SetupTime},
+		{"upload_time", m.UploadTime},
+		{"execution_wall_time", m.ExecutionWallTime},
+		{"process_outputs_time", m.ProcessOutputsTime},
+		{"retry_time", m.RetryTime},
+	}
+	for _, d := range durations {
+		if d.value == nil {
+			continue
+		}
+		if err := formatDuration(w, innerIndent, d.name, d.value); err != nil {
+			return err
+		}
+	}
+	counters := []struct {
+		name  string
+		value int64
+	}{
+		{"input_bytes", m.InputBytes},
+		{"input_files", m.InputFiles},
+		{"memory_estimate_bytes", m.MemoryEstimateBytes},
+		{"input_bytes_limit", m.InputBytesLimit},
+		{"input_files_limit", m.InputFilesLimit},
+		{"output_bytes_limit", m.OutputBytesLimit},
+		{"output_files_limit", m.OutputFilesLimit},
+		{"memory_bytes_limit", m.MemoryBytesLimit},
+	}
+	for _, c := range counters {
+		if err := writeInt64Field(w, innerIndent, c.name, c.value); err != nil {
+			return err
+		}
+	}
+	if m.StartTime != nil {
+		if err := formatTimestamp(w, innerIndent, "start_time", m.StartTime); err != nil {
+			return err
+		}
+	}
+	if err := formatUnknownFields(w, innerIndent, m.ProtoReflect().GetUnknown()); err != nil {
+		return err
+	}
 	if _, err := fmt.Fprintf(w, "%s}\n", indent); err != nil {
 		return err
 	}
 	return nil
 }
 
+func formatDuration(w io.Writer, indent, name string, d *durationpb.Duration) error {
+	return formatSecondsNanos(w, indent, name, d.Seconds, d.Nanos)
+}
+
+func formatTimestamp(w io.Writer, indent, name string, t *timestamppb.Timestamp) error {
+	return formatSecondsNanos(w, indent, name, t.Seconds, t.Nanos)
+}
+
+// formatSecondsNanos writes a google.protobuf.Duration or Timestamp, which
+// share the same field layout.
+func formatSecondsNanos(w io.Writer, indent, name string, seconds int64, nanos int32) error {
+	if _, err := fmt.Fprintf(w, "%s%s {\n", indent, name); err != nil {
+		return err
+	}
+	innerIndent := indent + "  "
+	if err := writeInt64Field(w, innerIndent, "seconds", seconds); err != nil {
+		return err
+	}
+	if err := writeInt32Field(w, innerIndent, "nanos", nanos); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s}\n", indent); err != nil {
+		return err
+	}
+	return nil
+}
+
+// formatUnknownFields writes fields not present in spawn.proto the way Java's
+// TextFormat prints an UnknownFieldSet: by field number after the known
+// fields, with length-delimited values shown as a nested message when they
+// parse as one and as a quoted string otherwise. Each message of spawn.proto
+// writes its own unknown fields, so those nested in a File or Digest are
+// printed inside it.
+func formatUnknownFields(w io.Writer, indent string, raw []byte) error {
+	fields, ok := parseUnknownFields(raw)
+	if !ok {
+		return nil
+	}
+	for _, f := range fields {
+		var err error
+		switch f.typ {
+		case protowire.VarintType:
+			_, err = fmt.Fprintf(w, "%s%d: %d\n", indent, f.num, f.varint)
+		case protowire.Fixed32Type:
+			_, err = fmt.Fprintf(w, "%s%d: 0x%08x\n", indent, f.num, f.varint)
+		case protowire.Fixed64Type:
+			_, err = fmt.Fprintf(w, "%s%d: 0x%016x\n", indent, f.num, f.varint)
+		case protowire.BytesType, protowire.StartGroupType:
+			if _, nested := parseUnknownFields(f.bytes); nested || f.typ == protowire.StartGroupType {
+				if _, err = fmt.Fprintf(w, "%s%d {\n", indent, f.num); err != nil {
+					return err
+				}
+				if err = formatUnknownFields(w, indent+"  ", f.bytes); err != nil {
+					return err
+				}
+				_, err = fmt.Fprintf(w, "%s}\n", indent)
+			} else {
+				_, err = fmt.Fprintf(w, "%s%d: %s\n", indent, f.num, quoteBytes(f.bytes))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type unknownField struct {
+	num    protowire.Number
+	typ    protowire.Type
+	varint uint64
+	bytes  []byte
+}
+
+// parseUnknownFields decodes raw wire-format fields, stably sorted by field
+// number. It reports false if raw is not well-formed.
+func parseUnknownFields(raw []byte) ([]unknownField, bool) {
+	var fields []unknownField
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return nil, false
+		}
+		raw = raw[n:]
+		f := unknownField{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(raw)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(raw)
+			f.varint = uint64(v)
+		case protowire.Fixed64Type:
+			f.varint, n = protowire.ConsumeFixed64(raw)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(raw)
+		case protowire.StartGroupType:
+			f.bytes, n = protowire.ConsumeGroup(num, raw)
+		default:
+			return nil, false
+		}
+		if n < 0 {
+			return nil, false
+		}
+		raw = raw[n:]
+		fields = append(fields, f)
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].num < fields[j].num })
+	return fields, true
+}
+
 func writeStringField(w io.Writer, indent, name, value string) error {
 	if value == "" {
 		return nil
@@ -172,6 +389,16 @@ func writeInt32Field(w io.Writer, indent, name string, value int32) error {
 // quoteString returns a double-quoted string with C-style escaping matching
 // Java's protobuf TextFormat output.
 func quoteString(s string) string {
+	return quote(s, false)
+}
+
+// quoteBytes is quoteString for raw bytes, which may not be UTF-8: bytes
+// outside ASCII are octal-escaped as well.
+func quoteBytes(b []byte) string {
+	return quote(string(b), true)
+}
+
+func quote(s string, escapeNonASCII bool) string {
 	var b strings.Builder
 	b.WriteByte('"')
 	for i := 0; i < len(s); i++ {
@@ -188,7 +415,7 @@ func quoteString(s string) string {
 		case '\\':
 			b.WriteString(`\\`)
 		default:
-			if c < 0x20 || c == 0x7f {
+			if c < 0x20 || c == 0x7f || (escapeNonASCII && c >= 0x80) {
 				// Octal escape for non-printable characters (matches Java TextFormat)
 				fmt.Fprintf(&b, "\\%03o", c)
 			} else {
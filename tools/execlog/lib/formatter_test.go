@@ -5,6 +5,10 @@ import (
 	"testing"
 
 	pb "tools/execlog/proto"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestQuoteString(t *testing.T) {
@@ -209,3 +213,151 @@ func TestFormatSpawnExec_ExitCodeNonZero(t *testing.T) {
 		t.Errorf("got:\n%s\nwant:\n%s", got, want)
 	}
 }
+
+func TestFormatSpawnExec_UpstreamFields(t *testing.T) {
+	exec := &pb.SpawnExec{
+		Inputs: []*pb.File{
+			{Path: "tools/gen", IsTool: true},
+			{Path: "in/link", SymlinkTargetPath: "../target"},
+		},
+		ExitCode:        2,
+		RemoteCacheable: true,
+		Walltime:        &durationpb.Duration{Seconds: 1, Nanos: 500},
+		TargetLabel:     "//pkg:target",
+		Digest:          &pb.Digest{Hash: "spawn", SizeBytes: 7},
+		Metrics: &pb.SpawnMetrics{
+			TotalTime:  &durationpb.Duration{Seconds: 3},
+			QueueTime:  &durationpb.Duration{Nanos: 20},
+			InputFiles: 4,
+			StartTime:  &timestamppb.Timestamp{Seconds: 1700000000},
+		},
+	}
+	var buf bytes.Buffer
+	if err := FormatSpawnExec(&buf, exec); err != nil {
+		t.Fatal(err)
+	}
+	want := `inputs {
+  path: "tools/gen"
+  is_tool: true
+}
+inputs {
+  path: "in/link"
+  symlink_target_path: "../target"
+}
+exit_code: 2
+remote_cacheable: true
+walltime {
+  seconds: 1
+  nanos: 500
+}
+target_label: "//pkg:target"
+digest {
+  hash: "spawn"
+  size_bytes: 7
+}
+metrics {
+  total_time {
+    seconds: 3
+  }
+  queue_time {
+    nanos: 20
+  }
+  input_files: 4
+  start_time {
+    seconds: 1700000000
+  }
+}
+`
+	got := buf.String()
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatSpawnExec_UnknownFields(t *testing.T) {
+	data, err := proto.Marshal(&pb.SpawnExec{Mnemonic: "Genrule"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fields a newer Bazel might write, deliberately out of order.
+	data = protowire.AppendTag(data, 31, protowire.BytesType)
+	data = protowire.AppendString(data, "\xff")
+	data = protowire.AppendTag(data, 30, protowire.VarintType)
+	data = protowire.AppendVarint(data, 7)
+	nested := protowire.AppendTag(nil, 1, protowire.VarintType)
+	nested = protowire.AppendVarint(nested, 1)
+	data = protowire.AppendTag(data, 32, protowire.BytesType)
+	data = protowire.AppendBytes(data, nested)
+	data = protowire.AppendTag(data, 33, protowire.Fixed32Type)
+	data = protowire.AppendFixed32(data, 255)
+
+	exec := &pb.SpawnExec{}
+	if err := proto.Unmarshal(data, exec); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := FormatSpawnExec(&buf, exec); err != nil {
+		t.Fatal(err)
+	}
+	want := `mnemonic: "Genrule"
+30: 7
+31: "\377"
+32 {
+  1: 1
+}
+33: 0x000000ff
+`
+	got := buf.String()
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatSpawnExec_NestedUnknownFields(t *testing.T) {
+	// withUnknown returns m encoded with an extra varint field num.
+	withUnknown := func(m proto.Message, num protowire.Number) []byte {
+		data, err := proto.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = protowire.AppendTag(data, num, protowire.VarintType)
+		return protowire.AppendVarint(data, 1)
+	}
+	digest := withUnknown(&pb.Digest{Hash: "abc"}, 10)
+	file := protowire.AppendTag(nil, 1, protowire.BytesType)
+	file = protowire.AppendString(file, "out/a")
+	file = protowire.AppendTag(file, 2, protowire.BytesType)
+	file = protowire.AppendBytes(file, digest)
+	file = protowire.AppendTag(file, 11, protowire.VarintType)
+	file = protowire.AppendVarint(file, 2)
+	platform := withUnknown(&pb.Platform{}, 12)
+	data := protowire.AppendTag(nil, 3, protowire.BytesType)
+	data = protowire.AppendBytes(data, platform)
+	data = protowire.AppendTag(data, 11, protowire.BytesType)
+	data = protowire.AppendBytes(data, file)
+
+	exec := &pb.SpawnExec{}
+	if err := proto.Unmarshal(data, exec); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := FormatSpawnExec(&buf, exec); err != nil {
+		t.Fatal(err)
+	}
+	want := `platform {
+  12: 1
+}
+actual_outputs {
+  path: "out/a"
+  digest {
+    hash: "abc"
+    10: 1
+  }
+  11: 2
+}
+`
+	got := buf.String()
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package execlog
+
+import (
+	"encoding/json"
+	"io"
+
+	pb "tools/execlog/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// jsonUnmarshalOptions tolerates fields added by newer Bazel versions, which
+// JSONParser counts in unknown instead.
+var jsonUnmarshalOptions = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// JSONParser reads the concatenated JSON SpawnExec objects written by
+// --execution_log_json_file, optionally filtering by runner.
+type JSONParser struct {
+	decoder          *json.Decoder
+	restrictToRunner string
+	// unknown, if set, counts the keys that spawn.proto does not define.
+	unknown *unknownFields
+}
+
+// NewJSONParser creates a parser that streams JSON-encoded SpawnExec messages
+// from r, optionally filtering to only those with the given runner.
+func NewJSONParser(r io.Reader, restrictToRunner string) *JSONParser {
+	return &JSONParser{
+		decoder:          json.NewDecoder(r),
+		restrictToRunner: restrictToRunner,
+	}
+}
+
+func (p *JSONParser) Next() (*pb.SpawnExec, error) {
+	for {
+		var raw json.RawMessage
+		err := p.decoder.Decode(&raw)
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		exec := &pb.SpawnExec{}
+		if err := jsonUnmarshalOptions.Unmarshal(raw, exec); err != nil {
+			return nil, err
+		}
+		if p.unknown != nil {
+			p.unknown.addJSON(raw, exec.ProtoReflect().Descriptor())
+		}
+		if p.restrictToRunner == "" || exec.Runner == p.restrictToRunner {
+			return exec, nil
+		}
+	}
+}
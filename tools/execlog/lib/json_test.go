@@ -0,0 +1,112 @@
+package execlog
+
+import (
+	"strings"
+	"testing"
+)
+
+// jsonLog mimics --execution_log_json_file output: pretty-printed objects
+// separated by blank lines, int64 values as strings, and fields this
+// version of spawn.proto does not know about.
+const jsonLog = `{
+  "commandArgs": ["/bin/echo", "hello"],
+  "environmentVariables": [{
+    "name": "PATH",
+    "value": "/usr/bin"
+  }],
+  "listedOutputs": ["out/a.txt"],
+  "remotable": true,
+  "cacheable": true,
+  "mnemonic": "Genrule",
+  "actualOutputs": [{
+    "path": "out/a.txt",
+    "digest": {
+      "hash": "abc123",
+      "sizeBytes": "10",
+      "hashFunctionName": "SHA-256"
+    }
+  }],
+  "runner": "linux-sandbox",
+  "futureField": {"nested": [1, 2, 3]}
+}
+
+{
+  "commandArgs": ["/bin/true"],
+  "mnemonic": "CppCompile",
+  "runner": "remote",
+  "targetLabel": "//pkg:lib"
+}
+`
+
+func TestJSONParser_NoFilter(t *testing.T) {
+	parser := NewJSONParser(strings.NewReader(jsonLog), "")
+
+	got1, err := parser.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got1.Mnemonic != "Genrule" {
+		t.Errorf("got mnemonic %q, want %q", got1.Mnemonic, "Genrule")
+	}
+	if len(got1.ActualOutputs) != 1 || got1.ActualOutputs[0].Digest.SizeBytes != 10 {
+		t.Errorf("actual outputs not decoded: %v", got1.ActualOutputs)
+	}
+	if len(got1.EnvironmentVariables) != 1 || got1.EnvironmentVariables[0].Value != "/usr/bin" {
+		t.Errorf("environment variables not decoded: %v", got1.EnvironmentVariables)
+	}
+
+	got2, err := parser.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got2.TargetLabel != "//pkg:lib" {
+		t.Errorf("got target label %q, want %q", got2.TargetLabel, "//pkg:lib")
+	}
+
+	got3, err := parser.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got3 != nil {
+		t.Errorf("expected nil at EOF, got %v", got3)
+	}
+}
+
+func TestJSONParser_WithFilter(t *testing.T) {
+	parser := NewJSONParser(strings.NewReader(jsonLog), "remote")
+
+	got, err := parser.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Mnemonic != "CppCompile" {
+		t.Fatalf("got %v, want CppCompile", got)
+	}
+	if got, _ := parser.Next(); got != nil {
+		t.Errorf("expected nil at EOF, got %v", got)
+	}
+}
+
+func TestJSONParser_Malformed(t *testing.T) {
+	parser := NewJSONParser(strings.NewReader(`{"commandArgs": "not a list"}`), "")
+	if _, err := parser.Next(); err == nil {
+		t.Error("expected error for malformed SpawnExec")
+	}
+}
+
+func TestNewLog_LeadingWhitespaceJSON(t *testing.T) {
+	log, err := NewLog(strings.NewReader("\n"+jsonLog), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log.Format != FormatJSON {
+		t.Fatalf("format = %v, want %v", log.Format, FormatJSON)
+	}
+	exec, err := log.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exec.Mnemonic != "Genrule" {
+		t.Errorf("got mnemonic %q, want %q", exec.Mnemonic, "Genrule")
+	}
+}
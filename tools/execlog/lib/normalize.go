@@ -0,0 +1,175 @@
+package execlog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	pb "tools/execlog/proto"
+)
+
+// RewriteRule replaces every match of Pattern with Replacement, which may
+// refer to capture groups as in regexp.Regexp.ReplaceAllString.
+type RewriteRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// ParseRewriteRule parses a rule written as "regex=replacement". The rule is
+// split at the last "=", so the regex may contain "=" but the replacement
+// may not.
+func ParseRewriteRule(s string) (RewriteRule, error) {
+	i := strings.LastIndex(s, "=")
+	if i <= 0 {
+		return RewriteRule{}, fmt.Errorf("rewrite rule %q: want regex=replacement", s)
+	}
+	re, err := regexp.Compile(s[:i])
+	if err != nil {
+		return RewriteRule{}, fmt.Errorf("rewrite rule %q: %w", s, err)
+	}
+	return RewriteRule{Pattern: re, Replacement: s[i+1:]}, nil
+}
+
+// pathChars matches the characters of an absolute path embedded in an
+// argument such as "-I/home/user/.cache/..." or a PATH-like list.
+const pathChars = `[^\s:="',;]`
+
+// segmentChars matches the characters of a single path segment.
+const segmentChars = `[^/\s:="',;]`
+
+// Built-in presets, listed in the order they are applied: sandbox and
+// execroot paths contain the output base, and the output base may contain a
+// bazel-out segment.
+var presets = []struct {
+	name  string
+	rules []RewriteRule
+}{
+	{"sandbox", []RewriteRule{{
+		regexp.MustCompile(`/` + pathChars + `*/sandbox/` + segmentChars + `+/[0-9]+/execroot/` + segmentChars + `+`),
+		"<execroot>",
+	}}},
+	{"execroot", []RewriteRule{{
+		regexp.MustCompile(`/` + pathChars + `*/execroot/` + segmentChars + `+`),
+		"<execroot>",
+	}}},
+	{"output_base", []RewriteRule{{
+		regexp.MustCompile(`/` + pathChars + `*/_bazel_` + segmentChars + `+/[0-9a-f]{32}\b`),
+		"<output_base>",
+	}}},
+	{"config", []RewriteRule{{
+		regexp.MustCompile(`bazel-out/` + segmentChars + `+/(bin|genfiles|testlogs|include)\b`),
+		"bazel-out/<config>/$1",
+	}}},
+}
+
+// PresetNames lists the built-in presets accepted by PresetRules.
+func PresetNames() []string {
+	names := make([]string, len(presets))
+	for i, p := range presets {
+		names[i] = p.name
+	}
+	return names
+}
+
+// PresetRules returns the rules of the named presets in their canonical
+// order, regardless of the order of names. "all" selects every preset.
+func PresetRules(names []string) ([]RewriteRule, error) {
+	want := make(map[string]bool)
+	for _, name := range names {
+		if name == "all" {
+			for _, p := range presets {
+				want[p.name] = true
+			}
+			continue
+		}
+		found := false
+		for _, p := range presets {
+			if p.name == name {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown path normalization preset %q (want one of %s, all)",
+				name, strings.Join(PresetNames(), ", "))
+		}
+		want[name] = true
+	}
+	var rules []RewriteRule
+	for _, p := range presets {
+		if want[p.name] {
+			rules = append(rules, p.rules...)
+		}
+	}
+	return rules, nil
+}
+
+// Normalizer rewrites the paths in a SpawnExec so that builds from different
+// output bases, workspaces or configurations compare equal.
+type Normalizer struct {
+	rules []RewriteRule
+}
+
+// NewNormalizer returns a Normalizer applying rules in order.
+func NewNormalizer(rules []RewriteRule) *Normalizer {
+	return &Normalizer{rules: rules}
+}
+
+func (n *Normalizer) rewrite(s string) string {
+	for _, rule := range n.rules {
+		s = rule.Pattern.ReplaceAllString(s, rule.Replacement)
+	}
+	return s
+}
+
+// Normalize rewrites exec in place: command arguments, environment variable
+// values, listed outputs, and the paths and symlink targets of inputs and
+// actual outputs. Inputs and actual outputs are re-sorted by path, since
+// rewriting can change their order.
+func (n *Normalizer) Normalize(exec *pb.SpawnExec) {
+	if len(n.rules) == 0 {
+		return
+	}
+	for i, arg := range exec.CommandArgs {
+		exec.CommandArgs[i] = n.rewrite(arg)
+	}
+	for _, env := range exec.EnvironmentVariables {
+		env.Value = n.rewrite(env.Value)
+	}
+	for i, output := range exec.ListedOutputs {
+		exec.ListedOutputs[i] = n.rewrite(output)
+	}
+	n.normalizeFiles(exec.Inputs)
+	n.normalizeFiles(exec.ActualOutputs)
+}
+
+func (n *Normalizer) normalizeFiles(files []*pb.File) {
+	for _, f := range files {
+		f.Path = n.rewrite(f.Path)
+		if f.SymlinkTargetPath != "" {
+			f.SymlinkTargetPath = n.rewrite(f.SymlinkTargetPath)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+}
+
+// NormalizingParser normalizes the paths of every SpawnExec read from input.
+type NormalizingParser struct {
+	input      Parser
+	normalizer *Normalizer
+}
+
+// NewNormalizingParser wraps input so that each record is normalized before
+// it is keyed or compared.
+func NewNormalizingParser(normalizer *Normalizer, input Parser) *NormalizingParser {
+	return &NormalizingParser{input: input, normalizer: normalizer}
+}
+
+func (p *NormalizingParser) Next() (*pb.SpawnExec, error) {
+	exec, err := p.input.Next()
+	if err != nil || exec == nil {
+		return nil, err
+	}
+	p.normalizer.Normalize(exec)
+	return exec, nil
+}
@@ -0,0 +1,118 @@
+package execlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPresetRules(t *testing.T) {
+	rules, err := PresetRules([]string{"all"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := NewNormalizer(rules)
+
+	tests := []struct {
+		in, want string
+	}{
+		{"bazel-out/k8-fastbuild/bin/pkg/a.o", "bazel-out/<config>/bin/pkg/a.o"},
+		{"bazel-out/k8-opt-exec-ST-0123abcd/bin/tool", "bazel-out/<config>/bin/tool"},
+		{"bazel-out/volatile-status.txt", "bazel-out/volatile-status.txt"},
+		{"bazel-out/k8-fastbuild/binary", "bazel-out/k8-fastbuild/binary"},
+		{
+			"-I/home/alice/.cache/bazel/_bazel_alice/0123456789abcdef0123456789abcdef/execroot/_main/bazel-out/k8-opt/bin",
+			"-I<execroot>/bazel-out/<config>/bin",
+		},
+		{
+			"/home/bob/.cache/bazel/_bazel_bob/fedcba9876543210fedcba9876543210/external/zlib/zlib.h",
+			"<output_base>/external/zlib/zlib.h",
+		},
+		{
+			"/tmp/ob/sandbox/linux-sandbox/42/execroot/my_ws/pkg/a.cc",
+			"<execroot>/pkg/a.cc",
+		},
+		{
+			"PATH=/usr/bin:/work/ob/execroot/_main/tools:/bin",
+			"PATH=/usr/bin:<execroot>/tools:/bin",
+		},
+	}
+	for _, tt := range tests {
+		if got := n.rewrite(tt.in); got != tt.want {
+			t.Errorf("rewrite(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPresetRules_Unknown(t *testing.T) {
+	_, err := PresetRules([]string{"config", "workspace"})
+	if err == nil || !strings.Contains(err.Error(), `"workspace"`) {
+		t.Errorf("got error %v, want unknown preset workspace", err)
+	}
+}
+
+func TestParseRewriteRule(t *testing.T) {
+	rule, err := ParseRewriteRule(`/home/[^/]+/ws=<workspace>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := NewNormalizer([]RewriteRule{rule}).rewrite("/home/carol/ws/pkg/BUILD"); got != "<workspace>/pkg/BUILD" {
+		t.Errorf("got %q, want %q", got, "<workspace>/pkg/BUILD")
+	}
+
+	// The regex may contain "=": the rule splits at the last one.
+	rule, err = ParseRewriteRule(`--sysroot=/opt/[a-z]+=<sysroot>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := NewNormalizer([]RewriteRule{rule}).rewrite("--sysroot=/opt/gcc"); got != "<sysroot>" {
+		t.Errorf("got %q, want %q", got, "<sysroot>")
+	}
+
+	for _, bad := range []string{"no-separator", "=replacement", "(unclosed=x"} {
+		if _, err := ParseRewriteRule(bad); err == nil {
+			t.Errorf("ParseRewriteRule(%q): expected error", bad)
+		}
+	}
+}
+
+func TestNormalizingParser(t *testing.T) {
+	build := func(config string) *pb.SpawnExec {
+		out := "bazel-out/" + config + "/bin/pkg/out.o"
+		return &pb.SpawnExec{
+			CommandArgs:   []string{"cc", "-o", out},
+			ListedOutputs: []string{out},
+			Inputs: []*pb.File{
+				{Path: "bazel-out/" + config + "/bin/pkg/gen.h"},
+				{Path: "pkg/a.cc"},
+			},
+			ActualOutputs: []*pb.File{{Path: out, Digest: &pb.Digest{Hash: "abc"}}},
+		}
+	}
+
+	rules, err := PresetRules([]string{"config"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalizer := NewNormalizer(rules)
+	var execs []*pb.SpawnExec
+	for _, config := range []string{"k8-fastbuild", "darwin_arm64-fastbuild"} {
+		var buf bytes.Buffer
+		writeDelimited(t, &buf, build(config))
+		exec, err := NewNormalizingParser(normalizer, NewFilteringParser(&buf, "")).Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		execs = append(execs, exec)
+	}
+
+	if !proto.Equal(execs[0], execs[1]) {
+		t.Errorf("normalized execs differ:\n%v\n%v", execs[0], execs[1])
+	}
+	if got := GetFirstOutput(execs[0]); got != "bazel-out/<config>/bin/pkg/out.o" {
+		t.Errorf("key = %q, want the normalized output", got)
+	}
+}
@@ -0,0 +1,285 @@
+package execlog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	pb "tools/execlog/proto"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrUnrecognizedFormat is returned when the input is not a binary, compact
+// or JSON execution log, compressed or not.
+var ErrUnrecognizedFormat = errors.New("unrecognized log format")
+
+// Format identifies the encoding of an execution log.
+type Format int
+
+const (
+	// FormatBinary is varint-delimited SpawnExec (--execution_log_binary_file).
+	FormatBinary Format = iota
+	// FormatCompact is zstd-compressed ExecLogEntry (--execution_log_compact_file).
+	FormatCompact
+	// FormatJSON is concatenated JSON SpawnExec (--execution_log_json_file).
+	FormatJSON
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatBinary:
+		return "binary"
+	case FormatCompact:
+		return "compact"
+	case FormatJSON:
+		return "json"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// sniffSize is how much of the (decompressed) stream is buffered to identify
+// its format. A first record that does not fit in it is only checked to
+// start with a known field.
+const sniffSize = 1 << 20
+
+// Log is an opened execution log: a Parser over its SpawnExec records along
+// with the detected format and the resources to release when done.
+type Log struct {
+	Parser
+
+	// Format is the detected log encoding.
+	Format Format
+	// Compression is "gzip" or "zstd" when the log was a compressed copy,
+	// and "" otherwise. The zstd framing of compact logs is not reported.
+	Compression string
+
+	closers []func() error
+	unknown unknownFields
+}
+
+// Next returns the next SpawnExec, noting any fields unknown to this version
+// of spawn.proto for UnknownFieldsWarning.
+func (l *Log) Next() (*pb.SpawnExec, error) {
+	exec, err := l.Parser.Next()
+	if exec != nil {
+		l.unknown.add(exec.ProtoReflect())
+	}
+	return exec, err
+}
+
+// UnknownFieldsWarning describes the records read so far that carried fields
+// this version of spawn.proto does not define, or returns "" if there were
+// none.
+func (l *Log) UnknownFieldsWarning() string {
+	return l.unknown.warning()
+}
+
+// Close releases the decompressors and the underlying file, if any.
+func (l *Log) Close() error {
+	var firstErr error
+	for i := len(l.closers) - 1; i >= 0; i-- {
+		if err := l.closers[i](); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	l.closers = nil
+	return firstErr
+}
+
+// OpenLog opens the execution log at path, transparently decompressing gzip
+// or zstd copies and choosing the decoder from the content rather than the
+// file name. The caller must Close the returned Log.
+func OpenLog(path, restrictToRunner string) (*Log, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	log, err := NewLog(f, restrictToRunner)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	log.closers = append([]func() error{f.Close}, log.closers...)
+	return log, nil
+}
+
+// NewLog detects the format of the execution log in r and returns a Log
+// reading from it. It returns an error wrapping ErrUnrecognizedFormat when
+// the content is not an execution log.
+func NewLog(r io.Reader, restrictToRunner string) (*Log, error) {
+	log := &Log{}
+	br := bufio.NewReaderSize(r, sniffSize)
+
+	if hasPrefix(br, gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad gzip header: %v", ErrUnrecognizedFormat, err)
+		}
+		log.Compression = "gzip"
+		log.closers = append(log.closers, gz.Close)
+		br = bufio.NewReaderSize(gz, sniffSize)
+	}
+	if hasPrefix(br, zstdMagic) {
+		dec, err := zstd.NewReader(br)
+		if err != nil {
+			log.Close()
+			return nil, fmt.Errorf("%w: bad zstd header: %v", ErrUnrecognizedFormat, err)
+		}
+		log.closers = append(log.closers, func() error { dec.Close(); return nil })
+		br = bufio.NewReaderSize(dec, sniffSize)
+		if looksLikeCompact(br) {
+			log.Format = FormatCompact
+			log.Parser = newCompactParser(br, restrictToRunner)
+			return log, nil
+		}
+		if log.Compression == "" {
+			log.Compression = "zstd"
+		}
+	}
+
+	switch {
+	case looksLikeJSON(br):
+		log.Format = FormatJSON
+		parser := NewJSONParser(br, restrictToRunner)
+		parser.unknown = &log.unknown
+		log.Parser = parser
+	case looksLikeBinary(br):
+		log.Format = FormatBinary
+		log.Parser = NewFilteringParser(br, restrictToRunner)
+	default:
+		log.Close()
+		return nil, fmt.Errorf("%w: not a binary, compact or JSON execution log", ErrUnrecognizedFormat)
+	}
+	return log, nil
+}
+
+func hasPrefix(br *bufio.Reader, magic []byte) bool {
+	buf, err := br.Peek(len(magic))
+	return err == nil && bytes.Equal(buf, magic)
+}
+
+// looksLikeJSON reports whether the data buffered in br, ignoring leading
+// whitespace, opens a JSON object: '{' followed by a key or '}'.
+func looksLikeJSON(br *bufio.Reader) bool {
+	buf, _ := br.Peek(sniffSize)
+	buf = bytes.TrimLeft(buf, " \t\r\n")
+	if len(buf) == 0 || buf[0] != '{' {
+		return false
+	}
+	buf = bytes.TrimLeft(buf[1:], " \t\r\n")
+	return len(buf) > 0 && (buf[0] == '"' || buf[0] == '}')
+}
+
+// looksLikeBinary reports whether br holds varint-delimited SpawnExec
+// records: either nothing at all, or a complete first record that decodes
+// and sets at least one known field. A first record too large to buffer,
+// such as a link action with many inputs, only needs to start with a known
+// field.
+func looksLikeBinary(br *bufio.Reader) bool {
+	record, whole, ok := peekRecord(br)
+	if !ok {
+		return false
+	}
+	if record == nil {
+		return true
+	}
+	if !whole {
+		return startsWithKnownField(record, (&pb.SpawnExec{}).ProtoReflect().Descriptor())
+	}
+	exec := &pb.SpawnExec{}
+	if err := proto.Unmarshal(record, exec); err != nil {
+		return false
+	}
+	return len(exec.ProtoReflect().GetUnknown()) < len(record)
+}
+
+// startsWithKnownField reports whether data begins with the tag of a field
+// of desc, with the wire type of that field.
+func startsWithKnownField(data []byte, desc protoreflect.MessageDescriptor) bool {
+	num, typ, n := protowire.ConsumeTag(data)
+	if n < 0 {
+		return false
+	}
+	field := desc.Fields().ByNumber(num)
+	if field == nil {
+		return false
+	}
+	want := protowire.VarintType
+	switch field.Kind() {
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.MessageKind:
+		want = protowire.BytesType
+	case protoreflect.GroupKind:
+		want = protowire.StartGroupType
+	case protoreflect.Fixed32Kind, protoreflect.Sfixed32Kind, protoreflect.FloatKind:
+		want = protowire.Fixed32Type
+	case protoreflect.Fixed64Kind, protoreflect.Sfixed64Kind, protoreflect.DoubleKind:
+		want = protowire.Fixed64Type
+	}
+	// Repeated scalars may be packed.
+	return typ == want || (field.IsList() && typ == protowire.BytesType)
+}
+
+// looksLikeCompact reports whether the first record in br is an ExecLogEntry
+// of a known type. Fields added by a later Bazel are left unknown and do not
+// matter, but a field this version knows with another wire type does: a
+// SpawnExec decoded as an ExecLogEntry leaves its first field (command_args,
+// a string) as unknown, since id is a varint.
+func looksLikeCompact(br *bufio.Reader) bool {
+	record, whole, ok := peekRecord(br)
+	if !ok || !whole || record == nil {
+		return false
+	}
+	entry := &pb.ExecLogEntry{}
+	if err := proto.Unmarshal(record, entry); err != nil {
+		return false
+	}
+	if entry.Type == nil {
+		return false
+	}
+	fields := entry.ProtoReflect().Descriptor().Fields()
+	for raw := entry.ProtoReflect().GetUnknown(); len(raw) > 0; {
+		num, _, n := protowire.ConsumeField(raw)
+		if n < 0 || fields.ByNumber(num) != nil {
+			return false
+		}
+		raw = raw[n:]
+	}
+	return true
+}
+
+// peekRecord returns the first varint-delimited record in br without
+// consuming it. It returns (nil, true, true) for an empty stream and false
+// when the prefix is not a valid length or the record is truncated. A record
+// larger than sniffSize is returned as the buffered prefix, with whole set to
+// false.
+func peekRecord(br *bufio.Reader) (record []byte, whole, ok bool) {
+	buf, _ := br.Peek(sniffSize)
+	if len(buf) == 0 {
+		return nil, true, true
+	}
+	size, n := binary.Uvarint(buf)
+	if n <= 0 || size == 0 {
+		return nil, false, false
+	}
+	if size > uint64(len(buf)-n) {
+		// The record continues past the buffer, unless the stream ended.
+		if len(buf) < sniffSize {
+			return nil, false, false
+		}
+		return buf[n:], false, true
+	}
+	return buf[n : n+int(size)], true, true
+}
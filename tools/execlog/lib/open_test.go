@@ -0,0 +1,318 @@
+package execlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "tools/execlog/proto"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := zstd.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestNewLog_DetectsFormats(t *testing.T) {
+	var binary bytes.Buffer
+	writeDelimited(t, &binary, &pb.SpawnExec{CommandArgs: []string{"/bin/true"}, Mnemonic: "Binary"})
+	writeDelimited(t, &binary, &pb.SpawnExec{Mnemonic: "Second"})
+
+	var compact bytes.Buffer
+	writeCompact(t, &compact, []*pb.ExecLogEntry{
+		{Type: &pb.ExecLogEntry_Invocation_{Invocation: &pb.ExecLogEntry_Invocation{HashFunctionName: "SHA-256"}}},
+		compactSpawn("Compact", "", 0),
+	})
+
+	tests := []struct {
+		name            string
+		data            []byte
+		wantFormat      Format
+		wantCompression string
+		wantMnemonic    string
+	}{
+		{"binary", binary.Bytes(), FormatBinary, "", "Binary"},
+		{"compact", compact.Bytes(), FormatCompact, "", "Compact"},
+		{"json", []byte(jsonLog), FormatJSON, "", "Genrule"},
+		{"gzip binary", gzipBytes(t, binary.Bytes()), FormatBinary, "gzip", "Binary"},
+		{"zstd binary", zstdBytes(t, binary.Bytes()), FormatBinary, "zstd", "Binary"},
+		{"gzip json", gzipBytes(t, []byte(jsonLog)), FormatJSON, "gzip", "Genrule"},
+		{"zstd json", zstdBytes(t, []byte(jsonLog)), FormatJSON, "zstd", "Genrule"},
+		{"gzip compact", gzipBytes(t, compact.Bytes()), FormatCompact, "gzip", "Compact"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := NewLog(bytes.NewReader(tt.data), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer log.Close()
+			if log.Format != tt.wantFormat {
+				t.Errorf("format = %v, want %v", log.Format, tt.wantFormat)
+			}
+			if log.Compression != tt.wantCompression {
+				t.Errorf("compression = %q, want %q", log.Compression, tt.wantCompression)
+			}
+			exec, err := log.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exec == nil || exec.Mnemonic != tt.wantMnemonic {
+				t.Errorf("got %v, want mnemonic %q", exec, tt.wantMnemonic)
+			}
+		})
+	}
+}
+
+func TestNewLog_LargeFirstRecord(t *testing.T) {
+	// A link action with many inputs has a first record larger than the
+	// buffer used to sniff the format.
+	link := &pb.SpawnExec{CommandArgs: []string{"/usr/bin/ld"}, Mnemonic: "CppLink"}
+	for i := 0; i < 15000; i++ {
+		link.Inputs = append(link.Inputs, &pb.File{
+			Path:   fmt.Sprintf("bazel-out/k8-fastbuild/bin/external/some_repository/pkg/obj_%05d.o", i),
+			Digest: &pb.Digest{Hash: strings.Repeat("a", 64), SizeBytes: 1234},
+		})
+	}
+	var buf bytes.Buffer
+	writeDelimited(t, &buf, link)
+	writeDelimited(t, &buf, &pb.SpawnExec{Mnemonic: "Second"})
+	if buf.Len() <= sniffSize {
+		t.Fatalf("first record of %d bytes fits in the sniff buffer", buf.Len())
+	}
+
+	log, err := NewLog(bytes.NewReader(buf.Bytes()), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	if log.Format != FormatBinary {
+		t.Errorf("format = %v, want %v", log.Format, FormatBinary)
+	}
+	for _, want := range []string{"CppLink", "Second"} {
+		exec, err := log.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exec == nil || exec.Mnemonic != want {
+			t.Errorf("got %v, want mnemonic %q", exec, want)
+		}
+	}
+}
+
+func TestNewLog_CompactWithNewerFields(t *testing.T) {
+	// A newer Bazel may add fields to Invocation and to ExecLogEntry itself.
+	invocation, err := proto.Marshal(&pb.ExecLogEntry_Invocation{HashFunctionName: "SHA-256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	invocation = protowire.AppendTag(invocation, 40, protowire.VarintType)
+	invocation = protowire.AppendVarint(invocation, 1)
+	var first []byte
+	first = protowire.AppendTag(first, 2, protowire.BytesType)
+	first = protowire.AppendBytes(first, invocation)
+	first = protowire.AppendTag(first, 900, protowire.BytesType)
+	first = protowire.AppendString(first, "new")
+
+	spawn, err := proto.Marshal(compactSpawn("Compact", "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := protowire.AppendBytes(protowire.AppendBytes(nil, first), spawn)
+
+	log, err := NewLog(bytes.NewReader(zstdBytes(t, data)), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	if log.Format != FormatCompact {
+		t.Errorf("format = %v, want %v", log.Format, FormatCompact)
+	}
+	if exec, err := log.Next(); err != nil || exec == nil || exec.Mnemonic != "Compact" {
+		t.Errorf("got %v, %v; want mnemonic Compact", exec, err)
+	}
+}
+
+func TestNewLog_Empty(t *testing.T) {
+	log, err := NewLog(bytes.NewReader(nil), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec, err := log.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exec != nil {
+		t.Errorf("expected nil for empty log, got %v", exec)
+	}
+}
+
+func TestNewLog_Unrecognized(t *testing.T) {
+	inputs := map[string][]byte{
+		"text":          []byte("this is a plain text file, not an execution log\n"),
+		"truncated":     {0x7f, 0x0a, 0x03},
+		"corrupt gzip":  append([]byte{}, gzipMagic...),
+		"json array":    []byte(`["not", "an", "object"]`),
+		"gzip of text":  gzipBytes(t, []byte("hello, world")),
+		"zstd of bytes": zstdBytes(t, []byte{0xff, 0xff, 0xff}),
+	}
+	for name, data := range inputs {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewLog(bytes.NewReader(data), "")
+			if !errors.Is(err, ErrUnrecognizedFormat) {
+				t.Fatalf("got error %v, want ErrUnrecognizedFormat", err)
+			}
+			if !strings.Contains(err.Error(), "unrecognized log format") {
+				t.Errorf("error message %q does not mention the format", err)
+			}
+		})
+	}
+}
+
+func TestOpenLog(t *testing.T) {
+	var binary bytes.Buffer
+	writeDelimited(t, &binary, &pb.SpawnExec{CommandArgs: []string{"/bin/true"}, Mnemonic: "Genrule"})
+	path := filepath.Join(t.TempDir(), "build.log.gz")
+	if err := os.WriteFile(path, gzipBytes(t, binary.Bytes()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := OpenLog(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec, err := log.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exec.Mnemonic != "Genrule" {
+		t.Errorf("got mnemonic %q, want %q", exec.Mnemonic, "Genrule")
+	}
+	if err := log.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+
+	if _, err := OpenLog(filepath.Join(t.TempDir(), "missing.log"), ""); !os.IsNotExist(err) {
+		t.Errorf("missing file: got %v, want not-exist error", err)
+	}
+}
+
+func TestLog_UnknownFieldsWarning(t *testing.T) {
+	// withUnknownVarint returns a copy of m with an extra varint field num,
+	// as a newer Bazel would write it.
+	withUnknownVarint := func(m proto.Message, num protowire.Number) []byte {
+		data, err := proto.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = protowire.AppendTag(data, num, protowire.VarintType)
+		return protowire.AppendVarint(data, 1)
+	}
+
+	topLevel := &pb.SpawnExec{}
+	if err := proto.Unmarshal(withUnknownVarint(&pb.SpawnExec{CommandArgs: []string{"/bin/true"}}, 40), topLevel); err != nil {
+		t.Fatal(err)
+	}
+	input := &pb.File{}
+	if err := proto.Unmarshal(withUnknownVarint(&pb.File{Path: "in/a.txt"}, 9), input); err != nil {
+		t.Fatal(err)
+	}
+	nested := &pb.SpawnExec{Mnemonic: "Nested", Inputs: []*pb.File{input}}
+
+	var buf bytes.Buffer
+	writeDelimited(t, &buf, topLevel)
+	writeDelimited(t, &buf, &pb.SpawnExec{Mnemonic: "Known"})
+	writeDelimited(t, &buf, nested)
+
+	log, err := NewLog(&buf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		exec, err := log.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exec == nil {
+			break
+		}
+	}
+
+	warning := log.UnknownFieldsWarning()
+	for _, want := range []string{"2 record(s)", "SpawnExec.40", "File.9"} {
+		if !strings.Contains(warning, want) {
+			t.Errorf("warning %q does not contain %q", warning, want)
+		}
+	}
+}
+
+func TestLog_NoUnknownFieldsWarning(t *testing.T) {
+	var buf bytes.Buffer
+	writeDelimited(t, &buf, &pb.SpawnExec{CommandArgs: []string{"/bin/true"}})
+	log, err := NewLog(&buf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := log.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if warning := log.UnknownFieldsWarning(); warning != "" {
+		t.Errorf("expected no warning, got %q", warning)
+	}
+}
+
+func TestLog_UnknownFieldsWarning_JSON(t *testing.T) {
+	data := strings.Replace(jsonLog, `"hash": "abc123",`, `"hash": "abc123", "future_digest_field": 1,`, 1)
+	log, err := NewLog(strings.NewReader(data), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		exec, err := log.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exec == nil {
+			break
+		}
+	}
+
+	warning := log.UnknownFieldsWarning()
+	for _, want := range []string{"1 record(s)", "SpawnExec.futureField", "Digest.future_digest_field", "not compared"} {
+		if !strings.Contains(warning, want) {
+			t.Errorf("warning %q does not contain %q", warning, want)
+		}
+	}
+}
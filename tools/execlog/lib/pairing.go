@@ -0,0 +1,83 @@
+package execlog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	pb "tools/execlog/proto"
+)
+
+// PairingStrategy selects the key that pairs a SpawnExec with the same
+// action in another log.
+type PairingStrategy string
+
+const (
+	// PairByFirstOutput keys actions by their first listed output.
+	PairByFirstOutput PairingStrategy = "first_output"
+	// PairByOutputs keys actions by the sorted set of their listed outputs,
+	// or of their actual outputs if none are listed.
+	PairByOutputs PairingStrategy = "outputs"
+	// PairByTarget keys actions by target label and mnemonic, numbered in
+	// log order, e.g. "//pkg:lib CppCompile #2".
+	PairByTarget PairingStrategy = "target"
+	// PairByDigest keys actions by the spawn digest Bazel logs for them,
+	// so only actions with identical inputs are paired.
+	PairByDigest PairingStrategy = "digest"
+)
+
+// PairingStrategies lists the strategies accepted by ParsePairingStrategy.
+func PairingStrategies() []PairingStrategy {
+	return []PairingStrategy{PairByFirstOutput, PairByOutputs, PairByTarget, PairByDigest}
+}
+
+// ParsePairingStrategy parses the name of a strategy. "" is PairByFirstOutput.
+func ParsePairingStrategy(name string) (PairingStrategy, error) {
+	if name == "" {
+		return PairByFirstOutput, nil
+	}
+	for _, s := range PairingStrategies() {
+		if string(s) == name {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown pairing strategy %q", name)
+}
+
+// Keyer assigns pairing keys to the SpawnExecs of one log. Keys may depend
+// on earlier SpawnExecs of the log, so use one Keyer per log and call Key
+// once per SpawnExec, in log order.
+type Keyer struct {
+	strategy PairingStrategy
+	ordinals map[string]int
+}
+
+// NewKeyer returns a Keyer for one log.
+func (s PairingStrategy) NewKeyer() *Keyer {
+	return &Keyer{strategy: s, ordinals: make(map[string]int)}
+}
+
+// Key returns the pairing key of exec, or "" if it cannot be paired.
+func (k *Keyer) Key(exec *pb.SpawnExec) string {
+	switch k.strategy {
+	case PairByOutputs:
+		outputs := append([]string(nil), exec.ListedOutputs...)
+		if len(outputs) == 0 {
+			for _, f := range exec.ActualOutputs {
+				outputs = append(outputs, f.Path)
+			}
+		}
+		sort.Strings(outputs)
+		return strings.Join(outputs, ",")
+	case PairByTarget:
+		if exec.TargetLabel == "" && exec.Mnemonic == "" {
+			return ""
+		}
+		prefix := strings.TrimSpace(exec.TargetLabel + " " + exec.Mnemonic)
+		k.ordinals[prefix]++
+		return fmt.Sprintf("%s #%d", prefix, k.ordinals[prefix])
+	case PairByDigest:
+		return exec.GetDigest().GetHash()
+	}
+	return getFirstOutput(exec)
+}
@@ -0,0 +1,81 @@
+package execlog
+
+import (
+	"bytes"
+	"testing"
+
+	pb "tools/execlog/proto"
+)
+
+func TestParsePairingStrategy(t *testing.T) {
+	if s, err := ParsePairingStrategy(""); err != nil || s != PairByFirstOutput {
+		t.Errorf(`ParsePairingStrategy("") = %q, %v; want first_output`, s, err)
+	}
+	if s, err := ParsePairingStrategy("digest"); err != nil || s != PairByDigest {
+		t.Errorf(`ParsePairingStrategy("digest") = %q, %v; want digest`, s, err)
+	}
+	if _, err := ParsePairingStrategy("first"); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
+
+func TestKeyer(t *testing.T) {
+	exec := &pb.SpawnExec{
+		ListedOutputs: []string{"out/b.txt", "out/a.txt"},
+		TargetLabel:   "//pkg:lib",
+		Mnemonic:      "CppCompile",
+		Digest:        &pb.Digest{Hash: "abc", SizeBytes: 3},
+	}
+	noOutputs := &pb.SpawnExec{
+		ActualOutputs: []*pb.File{{Path: "out/y"}, {Path: "out/x"}},
+		Mnemonic:      "TestRunner",
+	}
+
+	tests := []struct {
+		strategy PairingStrategy
+		execs    []*pb.SpawnExec
+		want     []string
+	}{
+		{PairByFirstOutput, []*pb.SpawnExec{exec, noOutputs}, []string{"out/b.txt", ""}},
+		{PairByOutputs, []*pb.SpawnExec{exec, noOutputs}, []string{"out/a.txt,out/b.txt", "out/x,out/y"}},
+		{PairByTarget, []*pb.SpawnExec{exec, noOutputs, exec}, []string{"//pkg:lib CppCompile #1", "TestRunner #1", "//pkg:lib CppCompile #2"}},
+		{PairByDigest, []*pb.SpawnExec{exec, noOutputs}, []string{"abc", ""}},
+	}
+	for _, tt := range tests {
+		keyer := tt.strategy.NewKeyer()
+		for i, e := range tt.execs {
+			if got := keyer.Key(e); got != tt.want[i] {
+				t.Errorf("%s: exec %d: got key %q, want %q", tt.strategy, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestReorderingParser_Strategy(t *testing.T) {
+	// Golden order by target: compile #1, compile #2, link #1.
+	golden := NewGoldenWithStrategy(PairByTarget)
+	golden.AddSpawnExec(&pb.SpawnExec{TargetLabel: "//a", Mnemonic: "Compile"})
+	golden.AddSpawnExec(&pb.SpawnExec{TargetLabel: "//a", Mnemonic: "Compile"})
+	golden.AddSpawnExec(&pb.SpawnExec{TargetLabel: "//a", Mnemonic: "Link"})
+
+	var buf bytes.Buffer
+	writeDelimited(t, &buf, &pb.SpawnExec{TargetLabel: "//a", Mnemonic: "Link", Runner: "L"})
+	writeDelimited(t, &buf, &pb.SpawnExec{TargetLabel: "//a", Mnemonic: "Compile", Runner: "C1"})
+	writeDelimited(t, &buf, &pb.SpawnExec{TargetLabel: "//a", Mnemonic: "Compile", Runner: "C2"})
+	writeDelimited(t, &buf, &pb.SpawnExec{TargetLabel: "//a", Mnemonic: "Compile", Runner: "C3"})
+	rp, err := NewReorderingParser(golden, NewFilteringParser(&buf, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The third compile has no counterpart and comes last.
+	for i, want := range []string{"C1", "C2", "L", "C3"} {
+		exec, err := rp.Next()
+		if err != nil || exec == nil {
+			t.Fatalf("index %d: got %v, %v", i, exec, err)
+		}
+		if exec.Runner != want {
+			t.Errorf("index %d: got %q, want %q", i, exec.Runner, want)
+		}
+	}
+}
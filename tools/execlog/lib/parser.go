@@ -60,24 +60,50 @@ func (p *FilteringParser) Next() (*pb.SpawnExec, error) {
 type Golden struct {
 	positions map[string]int
 	index     int
+	strategy  PairingStrategy
+	keyer     *Keyer
 }
 
+// NewGolden returns a Golden that pairs records by their first listed output.
 func NewGolden() *Golden {
-	return &Golden{positions: make(map[string]int)}
+	return NewGoldenWithStrategy(PairByFirstOutput)
 }
 
-// AddSpawnExec records the position of an exec by its first listed output.
+// NewGoldenWithStrategy returns a Golden that pairs records by the keys of
+// strategy.
+func NewGoldenWithStrategy(strategy PairingStrategy) *Golden {
+	return &Golden{positions: make(map[string]int), strategy: strategy, keyer: strategy.NewKeyer()}
+}
+
+// Strategy returns the pairing strategy of g.
+func (g *Golden) Strategy() PairingStrategy {
+	return g.strategy
+}
+
+// AddSpawnExec records the position of an exec by its pairing key. Later
+// execs with the same key keep the position of the first.
 func (g *Golden) AddSpawnExec(exec *pb.SpawnExec) {
-	key := getFirstOutput(exec)
-	if key != "" {
+	key := g.keyer.Key(exec)
+	if key == "" {
+		return
+	}
+	if _, ok := g.positions[key]; !ok {
 		g.positions[key] = g.index
-		g.index++
 	}
+	g.index++
 }
 
 // PositionFor returns the golden position for an exec, or -1 if not found.
+// Keys that depend on earlier records, as with PairByTarget, are computed
+// as if exec were the first record; use PositionForKey with a Keyer for
+// the other file instead.
 func (g *Golden) PositionFor(exec *pb.SpawnExec) int {
-	key := getFirstOutput(exec)
+	return g.PositionForKey(g.strategy.NewKeyer().Key(exec))
+}
+
+// PositionForKey returns the golden position for a pairing key, or -1 if
+// not found.
+func (g *Golden) PositionForKey(key string) int {
 	if key != "" {
 		if pos, ok := g.positions[key]; ok {
 			return pos
@@ -100,15 +126,23 @@ func GetFirstOutput(exec *pb.SpawnExec) string {
 
 // Priority queue for reordering SpawnExec records by golden position.
 
+// element is a record waiting in a priority queue. seq is its order in the
+// input, which keeps records with the same position in input order.
 type element struct {
 	position int
+	seq      int
 	exec     *pb.SpawnExec
 }
 
 type priorityQueue []*element
 
-func (pq priorityQueue) Len() int            { return len(pq) }
-func (pq priorityQueue) Less(i, j int) bool  { return pq[i].position < pq[j].position }
+func (pq priorityQueue) Len() int { return len(pq) }
+func (pq priorityQueue) Less(i, j int) bool {
+	if pq[i].position != pq[j].position {
+		return pq[i].position < pq[j].position
+	}
+	return pq[i].seq < pq[j].seq
+}
 func (pq priorityQueue) Swap(i, j int)       { pq[i], pq[j] = pq[j], pq[i] }
 func (pq *priorityQueue) Push(x interface{}) { *pq = append(*pq, x.(*element)) }
 func (pq *priorityQueue) Pop() interface{} {
@@ -134,6 +168,7 @@ func NewReorderingParser(golden *Golden, input Parser) (*ReorderingParser, error
 	rp := &ReorderingParser{}
 	pq := &priorityQueue{}
 	heap.Init(pq)
+	keyer := golden.strategy.NewKeyer()
 
 	for {
 		exec, err := input.Next()
@@ -143,9 +178,9 @@ func NewReorderingParser(golden *Golden, input Parser) (*ReorderingParser, error
 		if exec == nil {
 			break
 		}
-		position := golden.PositionFor(exec)
+		position := golden.PositionForKey(keyer.Key(exec))
 		if position >= 0 {
-			heap.Push(pq, &element{position: position, exec: exec})
+			heap.Push(pq, &element{position: position, seq: pq.Len(), exec: exec})
 		} else {
 			rp.uniqueActions = append(rp.uniqueActions, exec)
 		}
@@ -170,3 +170,35 @@ func TestReorderingParser(t *testing.T) {
 		t.Errorf("expected nil at end, got %v", exec)
 	}
 }
+
+func TestReorderingParser_DuplicateKeys(t *testing.T) {
+	// Golden order: a, b, a
+	golden := NewGolden()
+	golden.AddSpawnExec(&pb.SpawnExec{ListedOutputs: []string{"out/a.txt"}})
+	golden.AddSpawnExec(&pb.SpawnExec{ListedOutputs: []string{"out/b.txt"}})
+	golden.AddSpawnExec(&pb.SpawnExec{ListedOutputs: []string{"out/a.txt"}})
+	if pos := golden.PositionFor(&pb.SpawnExec{ListedOutputs: []string{"out/a.txt"}}); pos != 0 {
+		t.Errorf("a position = %d, want the first, 0", pos)
+	}
+
+	var buf bytes.Buffer
+	writeDelimited(t, &buf, &pb.SpawnExec{ListedOutputs: []string{"out/b.txt"}, Mnemonic: "B"})
+	for _, m := range []string{"A1", "A2", "A3"} {
+		writeDelimited(t, &buf, &pb.SpawnExec{ListedOutputs: []string{"out/a.txt"}, Mnemonic: m})
+	}
+	rp, err := NewReorderingParser(golden, NewFilteringParser(&buf, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Duplicates stay in input order.
+	for i, want := range []string{"A1", "A2", "A3", "B"} {
+		exec, err := rp.Next()
+		if err != nil || exec == nil {
+			t.Fatalf("index %d: got %v, %v", i, exec, err)
+		}
+		if exec.Mnemonic != want {
+			t.Errorf("index %d: got mnemonic %q, want %q", i, exec.Mnemonic, want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package execlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// unknownFields tallies fields that this version of spawn.proto does not
+// define, as written by newer Bazel versions. In binary and compact logs
+// the fields themselves stay in the decoded messages: proto.Equal compares
+// them and FormatSpawnExec prints them. protojson cannot keep them, so JSON
+// logs only count them and set dropped.
+type unknownFields struct {
+	records int
+	fields  map[string]bool
+	dropped bool
+}
+
+// add records the unknown fields of m and its nested messages.
+func (u *unknownFields) add(m protoreflect.Message) {
+	if u.collect(m) {
+		u.records++
+	}
+}
+
+// collect adds the unknown field numbers of m and its nested messages to
+// u.fields and reports whether there were any.
+func (u *unknownFields) collect(m protoreflect.Message) bool {
+	found := false
+	if raw := m.GetUnknown(); len(raw) > 0 {
+		found = true
+		if u.fields == nil {
+			u.fields = make(map[string]bool)
+		}
+		name := string(m.Descriptor().Name())
+		for len(raw) > 0 {
+			num, _, n := protowire.ConsumeField(raw)
+			if n < 0 {
+				u.fields[name+".?"] = true
+				break
+			}
+			u.fields[fmt.Sprintf("%s.%d", name, num)] = true
+			raw = raw[n:]
+		}
+	}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil {
+			return true
+		}
+		if fd.IsList() {
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				if u.collect(list.Get(i).Message()) {
+					found = true
+				}
+			}
+		} else if !fd.IsMap() {
+			if u.collect(v.Message()) {
+				found = true
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// addJSON records the keys of the JSON object raw, a message of type desc,
+// that desc and its nested messages do not define.
+func (u *unknownFields) addJSON(raw json.RawMessage, desc protoreflect.MessageDescriptor) {
+	if u.collectJSON(raw, desc) {
+		u.records++
+		u.dropped = true
+	}
+}
+
+// collectJSON adds the unknown keys of raw and its nested objects to
+// u.fields and reports whether there were any. Keys may be JSON or proto
+// field names, as protojson accepts both. Well-known types such as
+// Duration are encoded as strings and not descended into.
+func (u *unknownFields) collectJSON(raw json.RawMessage, desc protoreflect.MessageDescriptor) bool {
+	var object map[string]json.RawMessage
+	if json.Unmarshal(raw, &object) != nil {
+		return false
+	}
+	found := false
+	for key, value := range object {
+		fields := desc.Fields()
+		fd := fields.ByJSONName(key)
+		if fd == nil {
+			fd = fields.ByName(protoreflect.Name(key))
+		}
+		if fd == nil {
+			if u.fields == nil {
+				u.fields = make(map[string]bool)
+			}
+			u.fields[string(desc.Name())+"."+key] = true
+			found = true
+			continue
+		}
+		if fd.Message() == nil || fd.IsMap() || strings.HasPrefix(string(fd.Message().FullName()), "google.protobuf.") {
+			continue
+		}
+		if !fd.IsList() {
+			if u.collectJSON(value, fd.Message()) {
+				found = true
+			}
+			continue
+		}
+		var items []json.RawMessage
+		if json.Unmarshal(value, &items) == nil {
+			for _, item := range items {
+				if u.collectJSON(item, fd.Message()) {
+					found = true
+				}
+			}
+		}
+	}
+	return found
+}
+
+// warning describes the unknown fields seen so far, or returns "" if none.
+func (u *unknownFields) warning() string {
+	if u.records == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(u.fields))
+	for name := range u.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	kept := "The fields are kept and compared as raw bytes."
+	if u.dropped {
+		kept = "JSON logs cannot keep the fields, so they are not compared."
+	}
+	return fmt.Sprintf("%d record(s) contain fields unknown to this version of spawn.proto (%s); "+
+		"the log was probably written by a newer Bazel version. %s",
+		u.records, strings.Join(names, ", "), kept)
+}
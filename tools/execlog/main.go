@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -25,7 +26,7 @@ var (
 )
 
 func init() {
-	flag.Var(&logPaths, "log_path", "Input binary protobuf log file (can be specified 1-2 times)")
+	flag.Var(&logPaths, "log_path", "Input execution log file, optionally gzip or zstd compressed (can be specified 1-2 times)")
 	flag.Var(&outputPaths, "output_path", "Output text file (can be specified 0-2 times)")
 }
 
@@ -54,13 +55,11 @@ func output(p execlog.Parser, w io.Writer, golden *execlog.Golden) error {
 }
 
 func processFile(logPath, outputPath, runner string, golden *execlog.Golden) error {
-	f, err := os.Open(logPath)
+	parser, err := execlog.OpenLog(logPath, runner)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-
-	parser := execlog.NewFilteringParser(f, runner)
+	defer parser.Close()
 
 	var w io.Writer
 	if outputPath == "" {
@@ -77,21 +76,25 @@ func processFile(logPath, outputPath, runner string, golden *execlog.Golden) err
 	bw := bufio.NewWriter(w)
 	defer bw.Flush()
 
-	return output(parser, bw, golden)
+	if err := output(parser, bw, golden); err != nil {
+		return err
+	}
+	warnUnknownFields(logPath, parser)
+	return nil
 }
 
 func processSecondFile(logPath, outputPath, runner string, golden *execlog.Golden) error {
-	f, err := os.Open(logPath)
+	parser, err := execlog.OpenLog(logPath, runner)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	defer parser.Close()
 
-	parser := execlog.NewFilteringParser(f, runner)
 	reorderingParser, err := execlog.NewReorderingParser(golden, parser)
 	if err != nil {
 		return err
 	}
+	warnUnknownFields(logPath, parser)
 
 	outFile, err := os.Create(outputPath)
 	if err != nil {
@@ -105,6 +108,23 @@ func processSecondFile(logPath, outputPath, runner string, golden *execlog.Golde
 	return output(reorderingParser, bw, nil)
 }
 
+// warnUnknownFields reports records carrying fields from a newer spawn.proto.
+// They are still printed, by field number.
+func warnUnknownFields(logPath string, log *execlog.Log) {
+	if warning := log.UnknownFieldsWarning(); warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", logPath, warning)
+	}
+}
+
+// exitCode returns 2 for input that is not an execution log, and 1 for any
+// other processing error.
+func exitCode(err error) int {
+	if errors.Is(err, execlog.ErrUnrecognizedFormat) {
+		return 2
+	}
+	return 1
+}
+
 func main() {
 	flag.Parse()
 
@@ -144,13 +164,13 @@ func main() {
 
 	if err := processFile(logPath, output1Path, *restrictToRunner, golden); err != nil {
 		fmt.Fprintf(os.Stderr, "Error processing %s: %v\n", logPath, err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
 	if secondPath != "" {
 		if err := processSecondFile(secondPath, output2Path, *restrictToRunner, golden); err != nil {
 			fmt.Fprintf(os.Stderr, "Error processing %s: %v\n", secondPath, err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 	}
 }